@@ -0,0 +1,129 @@
+/*
+Word parallel bitmap blitter
+
+Pixels are copied 32 at time. Source bits are shifted in place when source and
+target do not share same bit alignment
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+)
+
+// RasterOp tells how source pixel (S) is combined with target pixel (D)
+type RasterOp byte
+
+const (
+	ROP_COPY    RasterOp = 0 // D = S
+	ROP_OR      RasterOp = 1 // D = D | S
+	ROP_AND     RasterOp = 2 // D = D & S
+	ROP_XOR     RasterOp = 3 // D = D ^ S
+	ROP_ANDNOT  RasterOp = 4 // D = D & ^S  (clears where source is set)
+	ROP_NOTCOPY RasterOp = 5 // D = ^S
+	ROP_ORNOT   RasterOp = 6 // D = D | ^S  (sets where source is clear)
+)
+
+// apply combines source word s into target word d. Only bits in mask are changed
+func (op RasterOp) apply(d uint32, s uint32, mask uint32) uint32 {
+	var r uint32
+	switch op {
+	case ROP_COPY:
+		r = s
+	case ROP_OR:
+		r = d | s
+	case ROP_AND:
+		r = d & s
+	case ROP_XOR:
+		r = d ^ s
+	case ROP_ANDNOT:
+		r = d &^ s
+	case ROP_NOTCOPY:
+		r = ^s
+	case ROP_ORNOT:
+		r = d | ^s
+	default:
+		return d
+	}
+	return (d &^ mask) | (r & mask)
+}
+
+// fetchBits returns 32 bits from bit stream starting at bit position. Bits over end are zero
+func fetchBits(src []uint32, bit int) uint32 {
+	index := bit >> 5
+	shift := uint32(bit & 31)
+	if len(src) <= index {
+		return 0
+	}
+	result := src[index] >> shift
+	if shift != 0 && index+1 < len(src) {
+		result |= src[index+1] << (32 - shift)
+	}
+	return result
+}
+
+// blitBits combines n bits from src (starting from srcBit) into dst (starting from dstBit). Target is processed one word at time
+func blitBits(dst []uint32, dstBit int, src []uint32, srcBit int, n int, op RasterOp) {
+	for 0 < n {
+		index := dstBit >> 5
+		shift := uint32(dstBit & 31)
+		k := min(32-int(shift), n)
+
+		mask := uint32(0xFFFFFFFF) >> uint32(32-k) << shift
+		dst[index] = op.apply(dst[index], fetchBits(src, srcBit)<<shift, mask)
+
+		dstBit += k
+		srcBit += k
+		n -= k
+	}
+}
+
+// clipBlit limits source area and target corner so that both stay inside bitmaps. Returns source area actually used and target corner of it
+func (p *MonoBitmap) clipBlit(source *MonoBitmap, sourceArea image.Rectangle, targetCorner image.Point) (image.Rectangle, image.Point) {
+	area := sourceArea.Intersect(source.LocalBounds())
+	targetCorner = targetCorner.Add(area.Min.Sub(sourceArea.Min))
+
+	//Limit by target
+	if targetCorner.X < 0 {
+		area.Min.X -= targetCorner.X
+		targetCorner.X = 0
+	}
+	if targetCorner.Y < 0 {
+		area.Min.Y -= targetCorner.Y
+		targetCorner.Y = 0
+	}
+	area.Max.X = min(area.Max.X, area.Min.X+p.W-targetCorner.X)
+	area.Max.Y = min(area.Max.Y, area.Min.Y+p.H-targetCorner.Y)
+	if area.Empty() {
+		return image.Rectangle{}, targetCorner
+	}
+	return area, targetCorner
+}
+
+// DrawBitmapOp draws source area on bitmap at targetCorner, combining pixels with raster operation. Drawing is clipped on both bitmaps
+func (p *MonoBitmap) DrawBitmapOp(source MonoBitmap, sourceArea image.Rectangle, targetCorner image.Point, op RasterOp) {
+	area, corner := p.clipBlit(&source, sourceArea, targetCorner)
+	n := area.Dx()
+	if n <= 0 {
+		return
+	}
+	p.markDirty(image.Rectangle{Min: corner, Max: corner.Add(area.Size())})
+	if sharesStorage(p.Pix, source.Pix) { //Drawing on itself or on view of same bitmap, take copy so overlapping areas stay intact
+		source.Pix = append([]uint32{}, source.Pix...)
+	}
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		blitBits(p.Pix, p.rowBit(corner.Y+y-area.Min.Y)+corner.X, source.Pix, source.rowBit(y)+area.Min.X, n, op)
+	}
+}
+
+// rowBit returns bit index of first pixel on row y
+func (p *MonoBitmap) rowBit(y int) int {
+	return p.Stride*y*32 + p.OffsetX
+}
+
+// sharesStorage checks are slices from same backing array. Slices of same array end on same element
+func sharesStorage(a []uint32, b []uint32) bool {
+	if cap(a) == 0 || cap(b) == 0 {
+		return false
+	}
+	return &a[:cap(a)][cap(a)-1] == &b[:cap(b)][cap(b)-1]
+}
@@ -0,0 +1,134 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func randomBitmap(rnd *rand.Rand, w int, h int) gomonochromebitmap.MonoBitmap {
+	result := gomonochromebitmap.NewMonoBitmap(w, h, false)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			result.SetPix(x, y, rnd.Intn(2) == 1)
+		}
+	}
+	return result
+}
+
+func refOp(op gomonochromebitmap.RasterOp, d bool, s bool) bool {
+	switch op {
+	case gomonochromebitmap.ROP_COPY:
+		return s
+	case gomonochromebitmap.ROP_OR:
+		return d || s
+	case gomonochromebitmap.ROP_AND:
+		return d && s
+	case gomonochromebitmap.ROP_XOR:
+		return d != s
+	case gomonochromebitmap.ROP_ANDNOT:
+		return d && !s
+	case gomonochromebitmap.ROP_NOTCOPY:
+		return !s
+	case gomonochromebitmap.ROP_ORNOT:
+		return d || !s
+	}
+	return d
+}
+
+func TestDrawBitmapOp(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	ops := []gomonochromebitmap.RasterOp{
+		gomonochromebitmap.ROP_COPY, gomonochromebitmap.ROP_OR, gomonochromebitmap.ROP_AND, gomonochromebitmap.ROP_XOR,
+		gomonochromebitmap.ROP_ANDNOT, gomonochromebitmap.ROP_NOTCOPY, gomonochromebitmap.ROP_ORNOT}
+
+	for round := 0; round < 200; round++ {
+		src := randomBitmap(rnd, 1+rnd.Intn(90), 1+rnd.Intn(20))
+		dst := randomBitmap(rnd, 1+rnd.Intn(90), 1+rnd.Intn(20))
+		op := ops[round%len(ops)]
+
+		area := image.Rect(rnd.Intn(src.W+4)-2, rnd.Intn(src.H+4)-2, rnd.Intn(src.W+4)-2, rnd.Intn(src.H+4)-2).Canon()
+		corner := image.Point{X: rnd.Intn(dst.W+20) - 10, Y: rnd.Intn(dst.H+10) - 5}
+
+		expected := gomonochromebitmap.NewMonoBitmap(dst.W, dst.H, false)
+		for y := 0; y < dst.H; y++ {
+			for x := 0; x < dst.W; x++ {
+				v := dst.GetPix(x, y)
+				sp := image.Point{X: x - corner.X + area.Min.X, Y: y - corner.Y + area.Min.Y}
+				if sp.In(area) && sp.In(src.Bounds()) {
+					v = refOp(op, v, src.GetPix(sp.X, sp.Y))
+				}
+				expected.SetPix(x, y, v)
+			}
+		}
+
+		dst.DrawBitmapOp(src, area, corner, op)
+		for y := 0; y < dst.H; y++ {
+			for x := 0; x < dst.W; x++ {
+				if dst.GetPix(x, y) != expected.GetPix(x, y) {
+					t.Fatalf("round %v op %v area %v corner %v: mismatch at (%v,%v)", round, op, area, corner, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestDrawBitmapModes(t *testing.T) {
+	src := gomonochromebitmap.NewMonoBitmap(2, 1, false)
+	src.SetPix(0, 0, true)
+
+	testCases := []struct {
+		drawTrue, drawFalse, invert bool
+		bg                          bool
+		expected                    [2]bool
+	}{
+		{true, true, false, true, [2]bool{true, false}},
+		{true, true, true, false, [2]bool{false, true}},
+		{true, false, false, false, [2]bool{true, false}},
+		{true, false, true, true, [2]bool{false, true}},
+		{false, true, false, true, [2]bool{true, false}},
+		{false, true, true, false, [2]bool{false, true}},
+		{false, false, false, true, [2]bool{true, true}},
+	}
+	for i, tc := range testCases {
+		dst := gomonochromebitmap.NewMonoBitmap(2, 1, tc.bg)
+		dst.DrawBitmap(src, src.Bounds(), image.Point{}, tc.drawTrue, tc.drawFalse, tc.invert)
+		for x := 0; x < 2; x++ {
+			if dst.GetPix(x, 0) != tc.expected[x] {
+				t.Errorf("case %v: pixel %v is %v", i, x, dst.GetPix(x, 0))
+			}
+		}
+	}
+}
+
+func TestDrawBitmapReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		src := randomBitmap(rnd, 1+rnd.Intn(70), 1+rnd.Intn(10))
+		bg := randomBitmap(rnd, 1+rnd.Intn(100), 1+rnd.Intn(15))
+		corner := image.Pt(rnd.Intn(bg.W+20)-10, rnd.Intn(bg.H+10)-5)
+		for mode := 0; mode < 8; mode++ {
+			drawTrue, drawFalse, invert := mode&1 != 0, mode&2 != 0, mode&4 != 0
+			expected := bg.Clone()
+			drawBitmapPixelByPixel(&expected, src, corner, drawTrue, drawFalse, invert)
+			dst := bg.Clone()
+			dst.DrawBitmap(src, src.LocalBounds(), corner, drawTrue, drawFalse, invert)
+			if !equalBitmaps(expected, dst) {
+				t.Fatalf("round %v drawTrue %v drawFalse %v invert %v differs", round, drawTrue, drawFalse, invert)
+			}
+		}
+	}
+}
+
+func TestDrawBitmapSelf(t *testing.T) {
+	b := gomonochromebitmap.NewMonoBitmap(70, 10, false)
+	b.Hline(0, 69, 0, true)
+	b.DrawBitmap(b, image.Rect(0, 0, 70, 9), image.Point{X: 3, Y: 1}, true, true, false)
+	for y := 0; y < 10; y++ {
+		if b.GetPix(3, y) != (y < 2) || b.GetPix(69, y) != (y < 2) || b.GetPix(2, y) != (y == 0) {
+			t.Errorf("scrolling on itself failed on row %v", y)
+		}
+	}
+}
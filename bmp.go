@@ -0,0 +1,153 @@
+/*
+1-bit Windows BMP reading and writing
+
+BMP stores rows bottom up (unless height is negative), rows are padded to
+4 bytes and first pixel is most significant bit. Pixel values are indexes to
+two entry palette.
+*/
+package gomonochromebitmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"io"
+)
+
+const (
+	bmpFileHeaderSize = 14
+	bmpInfoHeaderSize = 40
+	bmpCoreHeaderSize = 12
+)
+
+// BMPAutoOnIndex picks on index by palette. Brighter color is on
+const BMPAutoOnIndex = -1
+
+// DecodeBMP reads 1-bit uncompressed BMP. Pixels having palette index onIndex are on. With BMPAutoOnIndex brighter palette color is on
+func DecodeBMP(r io.Reader, onIndex int) (MonoBitmap, error) {
+	data, errRead := io.ReadAll(r)
+	if errRead != nil {
+		return MonoBitmap{}, errRead
+	}
+	if len(data) < bmpFileHeaderSize+bmpCoreHeaderSize || string(data[0:2]) != "BM" {
+		return MonoBitmap{}, fmt.Errorf("not BMP file")
+	}
+	le := binary.LittleEndian
+	pixOffset := int(le.Uint32(data[10:14]))
+	headerSize := int(le.Uint32(data[14:18]))
+
+	var w, h, bpp, compression int
+	paletteEntrySize := 4
+	switch {
+	case headerSize == bmpCoreHeaderSize:
+		w = int(le.Uint16(data[18:20]))
+		h = int(int16(le.Uint16(data[20:22])))
+		bpp = int(le.Uint16(data[24:26]))
+		paletteEntrySize = 3
+	case bmpInfoHeaderSize <= headerSize && bmpFileHeaderSize+bmpInfoHeaderSize <= len(data):
+		w = int(int32(le.Uint32(data[18:22])))
+		h = int(int32(le.Uint32(data[22:26])))
+		bpp = int(le.Uint16(data[28:30]))
+		compression = int(le.Uint32(data[30:34]))
+	default:
+		return MonoBitmap{}, fmt.Errorf("unsupported BMP header size %v", headerSize)
+	}
+	if bpp != 1 {
+		return MonoBitmap{}, fmt.Errorf("BMP is %v bits per pixel, only 1 is supported", bpp)
+	}
+	if compression != 0 {
+		return MonoBitmap{}, fmt.Errorf("compressed BMP is not supported")
+	}
+	topDown := h < 0
+	if topDown {
+		h = -h
+	}
+	if w <= 0 || h <= 0 {
+		return MonoBitmap{}, fmt.Errorf("invalid BMP dimensions %vx%v", w, h)
+	}
+	if errSize := checkDecodeSize(w, h); errSize != nil {
+		return MonoBitmap{}, fmt.Errorf("BMP header: %w", errSize)
+	}
+
+	if onIndex == BMPAutoOnIndex {
+		paletteOffset := bmpFileHeaderSize + headerSize
+		if len(data) < paletteOffset+2*paletteEntrySize {
+			return MonoBitmap{}, fmt.Errorf("BMP palette missing")
+		}
+		var palette [2]color.Color
+		for i := range palette {
+			e := data[paletteOffset+i*paletteEntrySize:]
+			palette[i] = color.RGBA{R: e[2], G: e[1], B: e[0], A: 255}
+		}
+		onIndex = 1
+		y0 := color.GrayModel.Convert(palette[0]).(color.Gray).Y
+		y1 := color.GrayModel.Convert(palette[1]).(color.Gray).Y
+		if y1 < y0 {
+			onIndex = 0
+		}
+	}
+
+	stride := ((w + 31) / 32) * 4
+	if len(data) < pixOffset+stride*h {
+		return MonoBitmap{}, fmt.Errorf("BMP pixel data truncated")
+	}
+	rowBytes := (w + 7) / 8
+	packed := make([]byte, 0, rowBytes*h)
+	for y := 0; y < h; y++ {
+		src := y
+		if !topDown {
+			src = h - 1 - y
+		}
+		packed = append(packed, data[pixOffset+src*stride:pixOffset+src*stride+rowBytes]...)
+	}
+	result, err := UnpackBytes(packed, w, h, PACK_HORIZONTAL, MSB_FIRST)
+	if err != nil {
+		return MonoBitmap{}, err
+	}
+	if onIndex == 0 {
+		result.Invert(result.LocalBounds())
+	}
+	return result, nil
+}
+
+// EncodeBMP writes bitmap as 1-bit BMP. Palette index 0 is offColor and index 1 (on pixels) is onColor
+func EncodeBMP(w io.Writer, bm *MonoBitmap, offColor color.Color, onColor color.Color) error {
+	stride := ((bm.W + 31) / 32) * 4
+	rowBytes := (bm.W + 7) / 8
+	pixOffset := bmpFileHeaderSize + bmpInfoHeaderSize + 2*4
+	imageSize := stride * bm.H
+
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	buf.WriteString("BM")
+	binary.Write(&buf, le, uint32(pixOffset+imageSize))
+	binary.Write(&buf, le, uint32(0)) //Reserved
+	binary.Write(&buf, le, uint32(pixOffset))
+
+	binary.Write(&buf, le, uint32(bmpInfoHeaderSize))
+	binary.Write(&buf, le, int32(bm.W))
+	binary.Write(&buf, le, int32(bm.H)) //Bottom up
+	binary.Write(&buf, le, uint16(1))   //Planes
+	binary.Write(&buf, le, uint16(1))   //Bits per pixel
+	binary.Write(&buf, le, uint32(0))   //No compression
+	binary.Write(&buf, le, uint32(imageSize))
+	binary.Write(&buf, le, int32(2835)) //72 DPI
+	binary.Write(&buf, le, int32(2835))
+	binary.Write(&buf, le, uint32(2)) //Colors used
+	binary.Write(&buf, le, uint32(0))
+
+	for _, c := range []color.Color{offColor, onColor} {
+		r, g, b, _ := c.RGBA()
+		buf.Write([]byte{byte(b >> 8), byte(g >> 8), byte(r >> 8), 0})
+	}
+
+	packed := bm.PackBytes(PACK_HORIZONTAL, MSB_FIRST)
+	padding := make([]byte, stride-rowBytes)
+	for y := bm.H - 1; 0 <= y; y-- {
+		buf.Write(packed[y*rowBytes : (y+1)*rowBytes])
+		buf.Write(padding)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
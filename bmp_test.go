@@ -0,0 +1,65 @@
+package gomonochromebitmap_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestBMPRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(13))
+	for _, w := range []int{1, 8, 31, 32, 33, 100} {
+		bm := randomBitmap(rnd, w, 7)
+		var buf bytes.Buffer
+		if err := gomonochromebitmap.EncodeBMP(&buf, &bm, color.Black, color.White); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() != 62+7*((w+31)/32)*4 {
+			t.Errorf("w=%v file size %v", w, buf.Len())
+		}
+		decoded, err := gomonochromebitmap.DecodeBMP(&buf, gomonochromebitmap.BMPAutoOnIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalBitmaps(bm, decoded) {
+			t.Errorf("w=%v round trip failed", w)
+		}
+	}
+}
+
+func TestBMPOnIndex(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(10, 3, false)
+	bm.SetPix(4, 1, true)
+
+	//Palette written other way around, index 1 is black
+	var buf bytes.Buffer
+	gomonochromebitmap.EncodeBMP(&buf, &bm, color.White, color.Black)
+	data := buf.Bytes()
+
+	auto, _ := gomonochromebitmap.DecodeBMP(bytes.NewReader(data), gomonochromebitmap.BMPAutoOnIndex)
+	if auto.GetPix(4, 1) || !auto.GetPix(0, 0) {
+		t.Errorf("auto on index should take white palette entry")
+	}
+	explicit, _ := gomonochromebitmap.DecodeBMP(bytes.NewReader(data), 1)
+	if !equalBitmaps(bm, explicit) {
+		t.Errorf("explicit on index failed")
+	}
+
+	//Top down file, negative height
+	binary.LittleEndian.PutUint32(data[22:], uint32(0xFFFFFFFD))
+	topDown, err := gomonochromebitmap.DecodeBMP(bytes.NewReader(data), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !topDown.GetPix(4, 1) || topDown.GetPix(4, 0) {
+		t.Errorf("top down failed")
+	}
+	data[28] = 8
+	if _, err := gomonochromebitmap.DecodeBMP(bytes.NewReader(data), 1); err == nil {
+		t.Errorf("8 bit BMP should fail")
+	}
+}
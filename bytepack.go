@@ -0,0 +1,195 @@
+/*
+Packing bitmap to bytes and generating source code
+
+Displays and firmware expect bitmaps as byte arrays. Layout is either
+horizontal (rows padded to full bytes) or vertical pages (each byte is
+column of 8 pixels, used by SSD1306 and other controllers).
+*/
+package gomonochromebitmap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// BitOrder tells which bit of byte is first pixel (leftmost or topmost)
+type BitOrder byte
+
+const (
+	MSB_FIRST BitOrder = 0
+	LSB_FIRST BitOrder = 1
+)
+
+// BytePacking tells how pixels are grouped into bytes
+type BytePacking byte
+
+const (
+	PACK_HORIZONTAL     BytePacking = 0 // Byte is 8 pixels on row. Each row starts on new byte
+	PACK_VERTICAL_PAGES BytePacking = 1 // Byte is 8 pixels on column. Page of 8 rows is written column by column
+)
+
+// bitMask returns mask for pixel number i (0-7) inside byte
+func (order BitOrder) bitMask(i int) byte {
+	if order == LSB_FIRST {
+		return 1 << uint(i)
+	}
+	return 0x80 >> uint(i)
+}
+
+// PackedSize returns number of bytes needed for w*h bitmap
+func PackedSize(w int, h int, packing BytePacking) int {
+	if packing == PACK_VERTICAL_PAGES {
+		return w * ((h + 7) / 8)
+	}
+	return h * ((w + 7) / 8)
+}
+
+// PackBytes returns bitmap as bytes, on pixel is bit 1
+func (p *MonoBitmap) PackBytes(packing BytePacking, order BitOrder) []byte {
+	result := make([]byte, PackedSize(p.W, p.H, packing))
+	if packing == PACK_VERTICAL_PAGES {
+		for y := 0; y < p.H; y++ {
+			mask := order.bitMask(y % 8)
+			offset := (y / 8) * p.W
+			for x := 0; x < p.W; x++ {
+				if p.GetPixNoCheck(x, y) {
+					result[offset+x] |= mask
+				}
+			}
+		}
+		return result
+	}
+	rowBytes := (p.W + 7) / 8
+	for y := 0; y < p.H; y++ {
+		for x := 0; x < p.W; x++ {
+			if p.GetPixNoCheck(x, y) {
+				result[y*rowBytes+x/8] |= order.bitMask(x % 8)
+			}
+		}
+	}
+	return result
+}
+
+// UnpackBytes creates w*h bitmap from packed bytes. Reverse of PackBytes
+func UnpackBytes(data []byte, w int, h int, packing BytePacking, order BitOrder) (MonoBitmap, error) {
+	if len(data) < PackedSize(w, h, packing) {
+		return MonoBitmap{}, fmt.Errorf("have %v bytes, %vx%v bitmap needs %v bytes", len(data), w, h, PackedSize(w, h, packing))
+	}
+	result := NewMonoBitmap(w, h, false)
+	rowBytes := (w + 7) / 8
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var v bool
+			if packing == PACK_VERTICAL_PAGES {
+				v = data[(y/8)*w+x]&order.bitMask(y%8) != 0
+			} else {
+				v = data[y*rowBytes+x/8]&order.bitMask(x%8) != 0
+			}
+			result.SetPixNoCheck(x, y, v)
+		}
+	}
+	return result, nil
+}
+
+// SourceLanguage selects syntax on WriteSource
+type SourceLanguage byte
+
+const (
+	LANG_C  SourceLanguage = 0
+	LANG_GO SourceLanguage = 1
+)
+
+// SourceSettings for WriteSource
+type SourceSettings struct {
+	Name         string //Identifier base name. Invalid characters are replaced with _
+	Language     SourceLanguage
+	Packing      BytePacking
+	BitOrder     BitOrder
+	BytesPerLine int  //0 = 12
+	Invert       bool //Off pixels are written as 1
+}
+
+// SourceIdentifier converts name into valid C and Go identifier
+func SourceIdentifier(name string) string {
+	var sb strings.Builder
+	for i, c := range name {
+		switch {
+		case c < unicode.MaxASCII && (unicode.IsLetter(c) || c == '_'):
+			sb.WriteRune(c)
+		case c < unicode.MaxASCII && unicode.IsDigit(c):
+			if i == 0 {
+				sb.WriteRune('_')
+			}
+			sb.WriteRune(c)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	if sb.Len() == 0 {
+		return "bitmap"
+	}
+	return sb.String()
+}
+
+// writeHexBytes writes comma separated 0x00 formatted bytes, n bytes per line
+func writeHexBytes(w *bufio.Writer, data []byte, n int, indent string, trailingComma bool) {
+	for i, b := range data {
+		if i%n == 0 {
+			w.WriteString(indent)
+		}
+		fmt.Fprintf(w, "0x%02x", b)
+		if i < len(data)-1 || trailingComma {
+			w.WriteByte(',')
+		}
+		if i%n == n-1 || i == len(data)-1 {
+			w.WriteByte('\n')
+		} else {
+			w.WriteByte(' ')
+		}
+	}
+}
+
+// WriteSource writes bitmap as C or Go source, with width and height constants
+func WriteSource(w io.Writer, bm *MonoBitmap, settings SourceSettings) error {
+	out := bufio.NewWriter(w)
+	name := SourceIdentifier(settings.Name)
+	perLine := settings.BytesPerLine
+	if perLine <= 0 {
+		perLine = 12
+	}
+	source := *bm
+	if settings.Invert {
+		source = bm.Clone()
+		source.Invert(source.LocalBounds())
+	}
+	data := source.PackBytes(settings.Packing, settings.BitOrder)
+
+	layout := "horizontal rows"
+	if settings.Packing == PACK_VERTICAL_PAGES {
+		layout = "vertical 8 pixel pages"
+	}
+	order := "MSB"
+	if settings.BitOrder == LSB_FIRST {
+		order = "LSB"
+	}
+
+	switch settings.Language {
+	case LANG_GO:
+		fmt.Fprintf(out, "// %s %vx%v bitmap, %s, %s first\n", name, bm.W, bm.H, layout, order)
+		fmt.Fprintf(out, "const (\n\t%sWidth  = %v\n\t%sHeight = %v\n)\n\n", name, bm.W, name, bm.H)
+		fmt.Fprintf(out, "var %s = []byte{\n", name)
+		writeHexBytes(out, data, perLine, "\t", true)
+		out.WriteString("}\n")
+	default:
+		upper := strings.ToUpper(name)
+		fmt.Fprintf(out, "/* %s %vx%v bitmap, %s, %s first */\n", name, bm.W, bm.H, layout, order)
+		fmt.Fprintf(out, "#define %s_WIDTH %v\n#define %s_HEIGHT %v\n\n", upper, bm.W, upper, bm.H)
+		fmt.Fprintf(out, "const unsigned char %s[%v] = {\n", name, len(data))
+		writeHexBytes(out, data, perLine, "    ", false)
+		out.WriteString("};\n")
+	}
+	return out.Flush()
+}
@@ -0,0 +1,71 @@
+package gomonochromebitmap_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestPackBytes(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(10, 9, false)
+	bm.SetPix(0, 0, true)
+	bm.SetPix(9, 8, true)
+
+	h := bm.PackBytes(gomonochromebitmap.PACK_HORIZONTAL, gomonochromebitmap.MSB_FIRST)
+	if len(h) != 18 || h[0] != 0x80 || h[17] != 0x40 {
+		t.Errorf("horizontal MSB packing failed % x", h)
+	}
+	v := bm.PackBytes(gomonochromebitmap.PACK_VERTICAL_PAGES, gomonochromebitmap.LSB_FIRST)
+	if len(v) != 20 || v[0] != 0x01 || v[19] != 0x01 {
+		t.Errorf("vertical LSB packing failed % x", v)
+	}
+
+	rnd := rand.New(rand.NewSource(5))
+	for _, packing := range []gomonochromebitmap.BytePacking{gomonochromebitmap.PACK_HORIZONTAL, gomonochromebitmap.PACK_VERTICAL_PAGES} {
+		for _, order := range []gomonochromebitmap.BitOrder{gomonochromebitmap.MSB_FIRST, gomonochromebitmap.LSB_FIRST} {
+			src := randomBitmap(rnd, 13, 19)
+			unpacked, err := gomonochromebitmap.UnpackBytes(src.PackBytes(packing, order), src.W, src.H, packing, order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equalBitmaps(src, unpacked) {
+				t.Errorf("packing %v order %v round trip failed", packing, order)
+			}
+		}
+	}
+	if _, err := gomonochromebitmap.UnpackBytes([]byte{1, 2}, 8, 3, gomonochromebitmap.PACK_HORIZONTAL, gomonochromebitmap.MSB_FIRST); err == nil {
+		t.Errorf("too short data must fail")
+	}
+}
+
+func TestWriteSource(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(8, 2, false)
+	bm.Hline(0, 3, 0, true)
+
+	var c bytes.Buffer
+	err := gomonochromebitmap.WriteSource(&c, &bm, gomonochromebitmap.SourceSettings{Name: "1st-icon", Language: gomonochromebitmap.LANG_C})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedC := "/* _1st_icon 8x2 bitmap, horizontal rows, MSB first */\n" +
+		"#define _1ST_ICON_WIDTH 8\n#define _1ST_ICON_HEIGHT 2\n\n" +
+		"const unsigned char _1st_icon[2] = {\n    0xf0, 0x00\n};\n"
+	if c.String() != expectedC {
+		t.Errorf("unexpected C source\n%s", c.String())
+	}
+
+	var g bytes.Buffer
+	err = gomonochromebitmap.WriteSource(&g, &bm, gomonochromebitmap.SourceSettings{Name: "icon", Language: gomonochromebitmap.LANG_GO,
+		Packing: gomonochromebitmap.PACK_VERTICAL_PAGES, BitOrder: gomonochromebitmap.LSB_FIRST, BytesPerLine: 4, Invert: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedGo := "// icon 8x2 bitmap, vertical 8 pixel pages, LSB first\n" +
+		"const (\n\ticonWidth  = 8\n\ticonHeight = 2\n)\n\n" +
+		"var icon = []byte{\n\t0x02, 0x02, 0x02, 0x02,\n\t0x03, 0x03, 0x03, 0x03,\n}\n"
+	if g.String() != expectedGo {
+		t.Errorf("unexpected Go source\n%s", g.String())
+	}
+}
@@ -0,0 +1,490 @@
+/*
+CCITT fax coding (ITU-T T.4 and T.6)
+
+Modified Huffman (MH), Group 3 one and two dimensional and Group 4 coding.
+Fax coding talks about white and black runs. White pixels are on pixels on
+MonoBitmap, same way as MonoColor(true) is white.
+*/
+package gomonochromebitmap
+
+import (
+	"fmt"
+)
+
+// CCITTMode selects fax coding. Values are same as TIFF compression tag values
+type CCITTMode byte
+
+const (
+	CCITT_MH CCITTMode = 2 // Modified Huffman runs, each row starts on byte boundary, no EOL codes
+	CCITT_G3 CCITTMode = 3 // T.4, each row starts with EOL. 1D or 2D coding
+	CCITT_G4 CCITTMode = 4 // T.6, 2D coding against previous row
+)
+
+// CCITTOptions for EncodeCCITT and DecodeCCITT
+type CCITTOptions struct {
+	Mode     CCITTMode
+	K        int  //G3 only: 0 or 1 codes all rows in 1D. K>1 codes every K:th row in 1D and rest in 2D (tag bit after EOL). Decoder only checks is K>1
+	AlignEOL bool //G3 only: fill bits are added so that each EOL ends on byte boundary
+	LSBFirst bool //Bits are packed least significant bit first (TIFF FillOrder 2)
+}
+
+// Terminating codes, run lengths 0-63
+var ccittWhiteTerminating = [64]string{
+	"00110101", "000111", "0111", "1000", "1011", "1100", "1110", "1111",
+	"10011", "10100", "00111", "01000", "001000", "000011", "110100", "110101",
+	"101010", "101011", "0100111", "0001100", "0001000", "0010111", "0000011", "0000100",
+	"0101000", "0101011", "0010011", "0100100", "0011000", "00000010", "00000011", "00011010",
+	"00011011", "00010010", "00010011", "00010100", "00010101", "00010110", "00010111", "00101000",
+	"00101001", "00101010", "00101011", "00101100", "00101101", "00000100", "00000101", "00001010",
+	"00001011", "01010010", "01010011", "01010100", "01010101", "00100100", "00100101", "01011000",
+	"01011001", "01011010", "01011011", "01001010", "01001011", "00110010", "00110011", "00110100",
+}
+
+var ccittBlackTerminating = [64]string{
+	"0000110111", "010", "11", "10", "011", "0011", "0010", "00011",
+	"000101", "000100", "0000100", "0000101", "0000111", "00000100", "00000111", "000011000",
+	"0000010111", "0000011000", "0000001000", "00001100111", "00001101000", "00001101100", "00000110111", "00000101000",
+	"00000010111", "00000011000", "000011001010", "000011001011", "000011001100", "000011001101", "000001101000", "000001101001",
+	"000001101010", "000001101011", "000011010010", "000011010011", "000011010100", "000011010101", "000011010110", "000011010111",
+	"000001101100", "000001101101", "000011011010", "000011011011", "000001010100", "000001010101", "000001010110", "000001010111",
+	"000001100100", "000001100101", "000001010010", "000001010011", "000000100100", "000000110111", "000000111000", "000000100111",
+	"000000101000", "000001011000", "000001011001", "000000101011", "000000101100", "000001011010", "000001100110", "000001100111",
+}
+
+// Makeup codes, run lengths 64,128...1728
+var ccittWhiteMakeup = [27]string{
+	"11011", "10010", "010111", "0110111", "00110110", "00110111", "01100100", "01100101",
+	"01101000", "01100111", "011001100", "011001101", "011010010", "011010011", "011010100", "011010101",
+	"011010110", "011010111", "011011000", "011011001", "011011010", "011011011", "010011000", "010011001",
+	"010011010", "011000", "010011011",
+}
+
+var ccittBlackMakeup = [27]string{
+	"0000001111", "000011001000", "000011001001", "000001011011", "000000110011", "000000110100", "000000110101", "0000001101100",
+	"0000001101101", "0000001001010", "0000001001011", "0000001001100", "0000001001101", "0000001110010", "0000001110011", "0000001110100",
+	"0000001110101", "0000001110110", "0000001110111", "0000001010010", "0000001010011", "0000001010100", "0000001010101", "0000001011010",
+	"0000001011011", "0000001100100", "0000001100101",
+}
+
+// Makeup codes shared by both colors, run lengths 1792,1856...2560
+var ccittExtendedMakeup = [13]string{
+	"00000001000", "00000001100", "00000001101", "000000010010", "000000010011", "000000010100", "000000010101",
+	"000000010110", "000000010111", "000000011100", "000000011101", "000000011110", "000000011111",
+}
+
+const (
+	ccittEOL        = "000000000001"
+	ccittPass       = "0001"
+	ccittHorizontal = "001"
+	ccittMaxRun     = 2560
+)
+
+// Vertical mode codes for a1-b1 = -3..3
+var ccittVertical = [7]string{"0000010", "000010", "010", "1", "011", "000011", "0000011"}
+
+// ccittRunCodes has codes of one color indexed by run length. Indexes 0-63 terminating, 64+ makeup codes by run/64
+type ccittRunCodes struct {
+	terminating [64]string
+	makeup      [41]string
+	decode      map[string]int
+}
+
+func newCCITTRunCodes(terminating [64]string, makeup [27]string) *ccittRunCodes {
+	result := ccittRunCodes{terminating: terminating, decode: make(map[string]int)}
+	copy(result.makeup[1:], makeup[:])
+	copy(result.makeup[28:], ccittExtendedMakeup[:])
+	for i, code := range result.terminating {
+		result.decode[code] = i
+	}
+	for i, code := range result.makeup {
+		if 0 < i {
+			result.decode[code] = i * 64
+		}
+	}
+	return &result
+}
+
+var (
+	ccittWhiteCodes = newCCITTRunCodes(ccittWhiteTerminating, ccittWhiteMakeup)
+	ccittBlackCodes = newCCITTRunCodes(ccittBlackTerminating, ccittBlackMakeup)
+)
+
+func ccittCodes(black bool) *ccittRunCodes {
+	if black {
+		return ccittBlackCodes
+	}
+	return ccittWhiteCodes
+}
+
+// ccittBitWriter collects bits MSB first
+type ccittBitWriter struct {
+	data  []byte
+	nBits int
+}
+
+func (p *ccittBitWriter) write(code string) {
+	for _, c := range code {
+		if p.nBits%8 == 0 {
+			p.data = append(p.data, 0)
+		}
+		if c == '1' {
+			p.data[len(p.data)-1] |= 0x80 >> uint(p.nBits%8)
+		}
+		p.nBits++
+	}
+}
+
+// alignTo adds zero bits until bit count is n modulo 8
+func (p *ccittBitWriter) alignTo(n int) {
+	for p.nBits%8 != n {
+		p.write("0")
+	}
+}
+
+func (p *ccittBitWriter) writeRun(run int, black bool) {
+	codes := ccittCodes(black)
+	for ccittMaxRun <= run {
+		p.write(codes.makeup[ccittMaxRun/64])
+		run -= ccittMaxRun
+	}
+	if 64 <= run {
+		p.write(codes.makeup[run/64])
+	}
+	p.write(codes.terminating[run%64])
+}
+
+// ccittBitReader reads bits MSB first
+type ccittBitReader struct {
+	data []byte
+	pos  int
+}
+
+func (p *ccittBitReader) bit() (byte, error) {
+	if len(p.data)*8 <= p.pos {
+		return 0, fmt.Errorf("CCITT data ended")
+	}
+	b := (p.data[p.pos/8] >> uint(7-p.pos%8)) & 1
+	p.pos++
+	return b, nil
+}
+
+// match reads code from table
+func (p *ccittBitReader) match(table map[string]int, maxLen int) (int, error) {
+	code := make([]byte, 0, maxLen)
+	for len(code) < maxLen {
+		b, err := p.bit()
+		if err != nil {
+			return 0, err
+		}
+		code = append(code, '0'+b)
+		if v, ok := table[string(code)]; ok {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid CCITT code %s at bit %v", code, p.pos)
+}
+
+// readRun reads makeup and terminating codes of one run
+func (p *ccittBitReader) readRun(black bool) (int, error) {
+	result := 0
+	for {
+		v, err := p.match(ccittCodes(black).decode, 13)
+		if err != nil {
+			return 0, err
+		}
+		result += v
+		if v < 64 {
+			return result, nil
+		}
+	}
+}
+
+// skipEOL consumes EOL (with possible fill bits) if next bits are one. Returns true if EOL was found
+func (p *ccittBitReader) skipEOL() bool {
+	zeros := 0
+	pos := p.pos
+	for pos < len(p.data)*8 && (p.data[pos/8]>>uint(7-pos%8))&1 == 0 {
+		zeros++
+		pos++
+	}
+	if zeros < 11 || len(p.data)*8 <= pos {
+		return false
+	}
+	p.pos = pos + 1
+	return true
+}
+
+func (p *ccittBitReader) alignByte() {
+	p.pos = (p.pos + 7) / 8 * 8
+}
+
+// ccittModes are decoded 2D mode codes. Vertical modes are 0-6 (a1-b1 = -3..3)
+const (
+	ccittModePass       = 7
+	ccittModeHorizontal = 8
+)
+
+var ccittModeDecode = func() map[string]int {
+	result := map[string]int{ccittPass: ccittModePass, ccittHorizontal: ccittModeHorizontal}
+	for i, code := range ccittVertical {
+		result[code] = i
+	}
+	return result
+}()
+
+// ccittNextChange returns first changing element after x (x=-1 is start of line) having color. Returns len(row) if not found
+func ccittNextChange(row []bool, x int, black bool) int {
+	for i := x + 1; i < len(row); i++ {
+		prev := false
+		if 0 < i {
+			prev = row[i-1]
+		}
+		if row[i] != prev && row[i] == black {
+			return i
+		}
+	}
+	return len(row)
+}
+
+// ccittB1B2 finds b1 (first change on reference row after a0 to opposite color of a0) and b2 (next change after b1)
+func ccittB1B2(ref []bool, a0 int, black bool) (int, int) {
+	b1 := ccittNextChange(ref, a0, !black)
+	b2 := ccittNextChange(ref, b1, black)
+	return b1, b2
+}
+
+// encode2D codes row against reference row (T.4 2D and T.6)
+func (p *ccittBitWriter) encode2D(row []bool, ref []bool) {
+	w := len(row)
+	a0 := -1
+	black := false
+	for a0 < w {
+		a1 := ccittNextChange(row, a0, !black)
+		b1, b2 := ccittB1B2(ref, a0, black)
+		switch {
+		case b2 < a1:
+			p.write(ccittPass)
+			a0 = b2
+		case -3 <= a1-b1 && a1-b1 <= 3:
+			p.write(ccittVertical[a1-b1+3])
+			a0 = a1
+			black = !black
+		default:
+			a2 := ccittNextChange(row, a1, black)
+			p.write(ccittHorizontal)
+			p.writeRun(a1-max(a0, 0), black)
+			p.writeRun(a2-a1, !black)
+			a0 = a2
+		}
+	}
+}
+
+// encode1D codes row as alternating white and black runs, starting from white
+func (p *ccittBitWriter) encode1D(row []bool) {
+	black := false
+	x := 0
+	for {
+		next := x
+		for next < len(row) && row[next] == black {
+			next++
+		}
+		p.writeRun(next-x, black)
+		x = next
+		if len(row) <= x {
+			return
+		}
+		black = !black
+	}
+}
+
+// ccittRow returns row y of bitmap as fax pixels. True is black (off pixel)
+func ccittRow(bm *MonoBitmap, y int, row []bool) {
+	for x := range row {
+		row[x] = !bm.GetPixNoCheck(x, y)
+	}
+}
+
+func ccittFillOrder(data []byte, lsbFirst bool) []byte {
+	if !lsbFirst {
+		return data
+	}
+	result := make([]byte, len(data))
+	for i, b := range data {
+		result[i] = reverseBits(b)
+	}
+	return result
+}
+
+func reverseBits(b byte) byte {
+	b = b>>4 | b<<4
+	b = (b&0xCC)>>2 | (b&0x33)<<2
+	return (b&0xAA)>>1 | (b&0x55)<<1
+}
+
+// EncodeCCITT codes bitmap with fax coding. White (on) pixels are fax white
+func EncodeCCITT(bm *MonoBitmap, opt CCITTOptions) []byte {
+	var out ccittBitWriter
+	row := make([]bool, bm.W)
+	ref := make([]bool, bm.W) //Imaginary all white row before first row
+
+	for y := 0; y < bm.H; y++ {
+		ccittRow(bm, y, row)
+		switch opt.Mode {
+		case CCITT_MH:
+			out.alignTo(0)
+			out.encode1D(row)
+		case CCITT_G3:
+			if opt.AlignEOL { //12 bit EOL ends on byte boundary
+				out.alignTo(4)
+			}
+			out.write(ccittEOL)
+			if 1 < opt.K {
+				if y%opt.K == 0 {
+					out.write("1")
+					out.encode1D(row)
+				} else {
+					out.write("0")
+					out.encode2D(row, ref)
+				}
+			} else {
+				out.encode1D(row)
+			}
+		default:
+			out.encode2D(row, ref)
+		}
+		row, ref = ref, row
+	}
+	if opt.Mode == CCITT_G4 {
+		out.write(ccittEOL + ccittEOL) //EOFB
+	}
+	return ccittFillOrder(out.data, opt.LSBFirst)
+}
+
+// decode1D reads alternating runs until row is full
+func (p *ccittBitReader) decode1D(row []bool) error {
+	x := 0
+	black := false
+	for x < len(row) {
+		run, err := p.readRun(black)
+		if err != nil {
+			return err
+		}
+		if len(row) < x+run {
+			return fmt.Errorf("CCITT run over row end")
+		}
+		for i := x; i < x+run; i++ {
+			row[i] = black
+		}
+		x += run
+		black = !black
+	}
+	return nil
+}
+
+// decode2D reads row coded against reference row
+func (p *ccittBitReader) decode2D(row []bool, ref []bool) error {
+	w := len(row)
+	fill := func(from int, to int, black bool) error {
+		if to < from || w < to {
+			return fmt.Errorf("invalid CCITT 2D coding, run %v-%v", from, to)
+		}
+		for i := from; i < to; i++ {
+			row[i] = black
+		}
+		return nil
+	}
+
+	a0 := -1
+	black := false
+	for a0 < w {
+		mode, err := p.match(ccittModeDecode, 7)
+		if err != nil {
+			return err
+		}
+		b1, b2 := ccittB1B2(ref, a0, black)
+		start := max(a0, 0)
+		switch mode {
+		case ccittModePass:
+			if err := fill(start, b2, black); err != nil {
+				return err
+			}
+			a0 = b2
+		case ccittModeHorizontal:
+			run1, err1 := p.readRun(black)
+			if err1 != nil {
+				return err1
+			}
+			run2, err2 := p.readRun(!black)
+			if err2 != nil {
+				return err2
+			}
+			if err := fill(start, start+run1, black); err != nil {
+				return err
+			}
+			if err := fill(start+run1, start+run1+run2, !black); err != nil {
+				return err
+			}
+			a0 = start + run1 + run2
+		default:
+			a1 := b1 + mode - 3
+			if err := fill(start, a1, black); err != nil {
+				return err
+			}
+			a0 = a1
+			black = !black
+		}
+	}
+	return nil
+}
+
+// DecodeCCITT decodes w*h bitmap from fax coded data
+func DecodeCCITT(data []byte, w int, h int, opt CCITTOptions) (MonoBitmap, error) {
+	if w <= 0 || h <= 0 {
+		return MonoBitmap{}, fmt.Errorf("invalid CCITT bitmap size %vx%v", w, h)
+	}
+	if errSize := checkDecodeSize(w, h); errSize != nil {
+		return MonoBitmap{}, fmt.Errorf("CCITT: %w", errSize)
+	}
+	in := ccittBitReader{data: ccittFillOrder(data, opt.LSBFirst)}
+	result := NewMonoBitmap(w, h, false)
+	row := make([]bool, w)
+	ref := make([]bool, w)
+
+	for y := 0; y < h; y++ {
+		var err error
+		switch opt.Mode {
+		case CCITT_MH:
+			in.alignByte()
+			err = in.decode1D(row)
+		case CCITT_G3:
+			if in.skipEOL() && opt.AlignEOL {
+				in.alignByte() //Some encoders add fill bits after EOL
+			}
+			twoD := false
+			if 1 < opt.K {
+				tag, errTag := in.bit()
+				if errTag != nil {
+					return MonoBitmap{}, errTag
+				}
+				twoD = tag == 0
+			}
+			if twoD {
+				err = in.decode2D(row, ref)
+			} else {
+				err = in.decode1D(row)
+			}
+		case CCITT_G4:
+			err = in.decode2D(row, ref)
+		default:
+			return MonoBitmap{}, fmt.Errorf("unsupported CCITT mode %v", opt.Mode)
+		}
+		if err != nil {
+			return MonoBitmap{}, fmt.Errorf("row %v: %w", y, err)
+		}
+		for x, black := range row {
+			result.SetPixNoCheck(x, y, !black)
+		}
+		row, ref = ref, row
+	}
+	return result, nil
+}
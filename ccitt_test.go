@@ -0,0 +1,85 @@
+package gomonochromebitmap_test
+
+import (
+	"bytes"
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+// runBitmap has runs of random length, codes differ from pixel noise
+func runBitmap(rnd *rand.Rand, w int, h int, avgRun int) gomonochromebitmap.MonoBitmap {
+	result := gomonochromebitmap.NewMonoBitmap(w, h, false)
+	for y := 0; y < h; y++ {
+		v := rnd.Intn(2) == 0
+		for x := 0; x < w; x++ {
+			if rnd.Intn(avgRun) == 0 {
+				v = !v
+			}
+			result.SetPixNoCheck(x, y, v)
+		}
+	}
+	return result
+}
+
+func TestCCITTRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(9))
+	modes := []gomonochromebitmap.CCITTOptions{
+		{Mode: gomonochromebitmap.CCITT_MH},
+		{Mode: gomonochromebitmap.CCITT_G3},
+		{Mode: gomonochromebitmap.CCITT_G3, AlignEOL: true},
+		{Mode: gomonochromebitmap.CCITT_G3, K: 4},
+		{Mode: gomonochromebitmap.CCITT_G3, K: 2, AlignEOL: true, LSBFirst: true},
+		{Mode: gomonochromebitmap.CCITT_G4},
+		{Mode: gomonochromebitmap.CCITT_G4, LSBFirst: true},
+	}
+	for _, opt := range modes {
+		for _, w := range []int{1, 7, 64, 153, 1800, 2600, 5000} {
+			for _, avgRun := range []int{1, 5, 300} {
+				bm := runBitmap(rnd, w, 9, avgRun)
+				data := gomonochromebitmap.EncodeCCITT(&bm, opt)
+				decoded, err := gomonochromebitmap.DecodeCCITT(data, w, 9, opt)
+				if err != nil {
+					t.Fatalf("%+v w=%v: %v", opt, w, err)
+				}
+				if !equalBitmaps(bm, decoded) {
+					t.Errorf("%+v w=%v run=%v round trip failed", opt, w, avgRun)
+				}
+			}
+		}
+	}
+}
+
+func TestCCITTKnownCodes(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(8, 2, true)
+	bm.SetPix(2, 1, false)
+
+	//Row 0: white 8 (10011). Row 1: white 2 (0111), black 1 (010), white 5 (1100)
+	mh := gomonochromebitmap.EncodeCCITT(&bm, gomonochromebitmap.CCITTOptions{Mode: gomonochromebitmap.CCITT_MH})
+	if !bytes.Equal(mh, []byte{0x98, 0x75, 0x80}) {
+		t.Errorf("MH coding %x", mh)
+	}
+	//Row 0: V0 (1). Row 1: horizontal (001) white 2 (0111) black 1 (010), V0 (1). EOFB
+	g4 := gomonochromebitmap.EncodeCCITT(&bm, gomonochromebitmap.CCITTOptions{Mode: gomonochromebitmap.CCITT_G4})
+	if !bytes.Equal(g4, []byte{0x97, 0x50, 0x01, 0x00, 0x10}) {
+		t.Errorf("G4 coding %x", g4)
+	}
+}
+
+func TestCCITTInvalid(t *testing.T) {
+	for _, opt := range []gomonochromebitmap.CCITTOptions{{Mode: gomonochromebitmap.CCITT_MH}, {Mode: gomonochromebitmap.CCITT_G3}, {Mode: gomonochromebitmap.CCITT_G4}} {
+		if _, err := gomonochromebitmap.DecodeCCITT([]byte{0x00, 0x00}, 16, 4, opt); err == nil {
+			t.Errorf("%+v no error on invalid data", opt)
+		}
+		if _, err := gomonochromebitmap.DecodeCCITT(nil, 16, 4, opt); err == nil {
+			t.Errorf("%+v no error on empty data", opt)
+		}
+		for _, size := range []image.Point{{-5, 4}, {16, -1}, {0, 4}, {16, 0}, {1 << 20, 1 << 20}} {
+			if _, err := gomonochromebitmap.DecodeCCITT([]byte{0x00, 0x00}, size.X, size.Y, opt); err == nil {
+				t.Errorf("%+v no error on size %v", opt, size)
+			}
+		}
+	}
+}
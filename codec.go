@@ -0,0 +1,382 @@
+/*
+Bitmap compression codecs
+
+Codecs compress packed bitmap bytes. Width and height are not stored, they
+are usually known by firmware. EncodeSmallest tries several codecs and
+prefixes result with tag byte of the winner, so DecodeTagged knows which one
+to use.
+
+LZSS output is compatible with heatshrink decoder having same window and
+lookahead sizes. Decoded heatshrink output is packed bitmap bytes.
+*/
+package gomonochromebitmap
+
+import (
+	"fmt"
+)
+
+// CodecTag identifies codec on tagged data
+type CodecTag byte
+
+const (
+	CODEC_RAW      CodecTag = 0
+	CODEC_RLE      CodecTag = 1
+	CODEC_PACKBITS CodecTag = 2
+	CODEC_LZSS     CodecTag = 3
+	CODEC_ROWXOR   CodecTag = 0x80 // Flag bit, combined with tag of inner codec
+)
+
+// maxDecodePixels limits size of bitmaps read from files, so hostile header can not allocate more than 128MB
+const maxDecodePixels = 1 << 30
+
+// checkDecodeSize returns error if w*h bitmap from file header is negative or too large to allocate
+func checkDecodeSize(w int, h int) error {
+	if w < 0 || h < 0 {
+		return fmt.Errorf("invalid bitmap size %vx%v", w, h)
+	}
+	if maxDecodePixels < w || maxDecodePixels < h || (h != 0 && maxDecodePixels/h < w) {
+		return fmt.Errorf("too large bitmap %vx%v, limit is %v pixels", w, h, maxDecodePixels)
+	}
+	return nil
+}
+
+// Codec compresses bitmaps
+type Codec interface {
+	Tag() CodecTag
+	Encode(bm *MonoBitmap) []byte
+	Decode(data []byte, w int, h int) (MonoBitmap, error)
+}
+
+// ByteLayout tells how bitmap is packed to bytes before compression
+type ByteLayout struct {
+	Packing  BytePacking
+	BitOrder BitOrder
+}
+
+// RawCodec stores packed bytes as is
+type RawCodec struct {
+	ByteLayout
+}
+
+func (p RawCodec) Tag() CodecTag { return CODEC_RAW }
+
+func (p RawCodec) Encode(bm *MonoBitmap) []byte {
+	return bm.PackBytes(p.Packing, p.BitOrder)
+}
+
+func (p RawCodec) Decode(data []byte, w int, h int) (MonoBitmap, error) {
+	return UnpackBytes(data, w, h, p.Packing, p.BitOrder)
+}
+
+// RLECodec is version 1 RLEencode format
+type RLECodec struct {
+	ActiveFirst bool
+}
+
+func (p RLECodec) Tag() CodecTag { return CODEC_RLE }
+
+func (p RLECodec) Encode(bm *MonoBitmap) []byte {
+	return bm.RLEencode(p.ActiveFirst)
+}
+
+func (p RLECodec) Decode(data []byte, w int, h int) (MonoBitmap, error) {
+	result := NewMonoBitmap(w, h, false)
+	err := result.RLEdecode(p.ActiveFirst, data)
+	return result, err
+}
+
+// PackBitsCodec is Apple PackBits (also used in TIFF) coding of packed bytes
+type PackBitsCodec struct {
+	ByteLayout
+}
+
+func (p PackBitsCodec) Tag() CodecTag { return CODEC_PACKBITS }
+
+func (p PackBitsCodec) Encode(bm *MonoBitmap) []byte {
+	return PackBits(bm.PackBytes(p.Packing, p.BitOrder))
+}
+
+func (p PackBitsCodec) Decode(data []byte, w int, h int) (MonoBitmap, error) {
+	packed, err := UnpackBits(data, PackedSize(w, h, p.Packing))
+	if err != nil {
+		return MonoBitmap{}, err
+	}
+	return UnpackBytes(packed, w, h, p.Packing, p.BitOrder)
+}
+
+// PackBits compresses data. Header byte 0-127 is followed by 1-128 literal bytes and 129-255 by byte repeated 2-128 times
+func PackBits(data []byte) []byte {
+	result := []byte{}
+	literalStart := 0
+	flushLiterals := func(end int) {
+		for literalStart < end {
+			n := min(end-literalStart, 128)
+			result = append(result, byte(n-1))
+			result = append(result, data[literalStart:literalStart+n]...)
+			literalStart += n
+		}
+	}
+	i := 0
+	for i < len(data) {
+		run := 1
+		for i+run < len(data) && run < 128 && data[i+run] == data[i] {
+			run++
+		}
+		if run < 3 { //Two byte run is not worth of breaking literals
+			i += run
+			continue
+		}
+		flushLiterals(i)
+		result = append(result, byte(257-run), data[i])
+		i += run
+		literalStart = i
+	}
+	flushLiterals(len(data))
+	return result
+}
+
+// UnpackBits decompresses PackBits data into size bytes
+func UnpackBits(data []byte, size int) ([]byte, error) {
+	result := make([]byte, 0, size)
+	i := 0
+	for len(result) < size {
+		if len(data) <= i {
+			return nil, fmt.Errorf("PackBits data ended at %v of %v bytes", len(result), size)
+		}
+		header := int(data[i])
+		i++
+		switch {
+		case header < 128:
+			n := header + 1
+			if len(data) < i+n {
+				return nil, fmt.Errorf("PackBits literal over data end")
+			}
+			result = append(result, data[i:i+n]...)
+			i += n
+		case header == 128: //No operation
+		default:
+			if len(data) <= i {
+				return nil, fmt.Errorf("PackBits repeat over data end")
+			}
+			for n := 257 - header; 0 < n; n-- {
+				result = append(result, data[i])
+			}
+			i++
+		}
+	}
+	if size < len(result) {
+		return nil, fmt.Errorf("PackBits data is %v bytes too long", len(result)-size)
+	}
+	return result, nil
+}
+
+// LZSSCodec is heatshrink compatible LZSS. Zero values use heatshrink defaults, window 8 and lookahead 4 bits
+type LZSSCodec struct {
+	ByteLayout
+	WindowBits    int //4-15, window is 2^WindowBits bytes
+	LookaheadBits int //3-WindowBits-1, longest match is 2^LookaheadBits bytes
+}
+
+func (p LZSSCodec) Tag() CodecTag { return CODEC_LZSS }
+
+func (p LZSSCodec) sizes() (int, int) {
+	w, l := p.WindowBits, p.LookaheadBits
+	if w == 0 {
+		w = 8
+	}
+	if l == 0 {
+		l = 4
+	}
+	return w, l
+}
+
+func (p LZSSCodec) Encode(bm *MonoBitmap) []byte {
+	w, l := p.sizes()
+	return LZSSCompress(bm.PackBytes(p.Packing, p.BitOrder), w, l)
+}
+
+func (p LZSSCodec) Decode(data []byte, w int, h int) (MonoBitmap, error) {
+	windowBits, lookaheadBits := p.sizes()
+	packed, err := LZSSDecompress(data, PackedSize(w, h, p.Packing), windowBits, lookaheadBits)
+	if err != nil {
+		return MonoBitmap{}, err
+	}
+	return UnpackBytes(packed, w, h, p.Packing, p.BitOrder)
+}
+
+// lzssBits writes and reads bit stream MSB first
+type lzssBits struct {
+	data []byte
+	pos  int //bit position
+}
+
+func (p *lzssBits) write(v int, n int) {
+	for i := n - 1; 0 <= i; i-- {
+		if p.pos%8 == 0 {
+			p.data = append(p.data, 0)
+		}
+		if (v>>uint(i))&1 != 0 {
+			p.data[p.pos/8] |= 0x80 >> uint(p.pos%8)
+		}
+		p.pos++
+	}
+}
+
+func (p *lzssBits) read(n int) (int, error) {
+	if len(p.data)*8 < p.pos+n {
+		return 0, fmt.Errorf("LZSS data ended")
+	}
+	v := 0
+	for i := 0; i < n; i++ {
+		v = v<<1 | int(p.data[p.pos/8]>>uint(7-p.pos%8))&1
+		p.pos++
+	}
+	return v, nil
+}
+
+// LZSSCompress compresses data in heatshrink format. Tag bit 1 is followed by literal byte, 0 by window offset-1 and count-1
+func LZSSCompress(data []byte, windowBits int, lookaheadBits int) []byte {
+	out := lzssBits{}
+	window := 1 << uint(windowBits)
+	lookahead := 1 << uint(lookaheadBits)
+	breakEven := (1 + windowBits + lookaheadBits) / 9 //Longest match that is not shorter than literals
+
+	for i := 0; i < len(data); {
+		bestLen, bestOffset := 0, 0
+		for offset := 1; offset <= min(window, i); offset++ {
+			n := 0
+			for n < lookahead && i+n < len(data) && data[i+n] == data[i+n-offset] {
+				n++
+			}
+			if bestLen < n {
+				bestLen, bestOffset = n, offset
+				if n == lookahead {
+					break
+				}
+			}
+		}
+		if breakEven < bestLen {
+			out.write(0, 1)
+			out.write(bestOffset-1, windowBits)
+			out.write(bestLen-1, lookaheadBits)
+			i += bestLen
+		} else {
+			out.write(1, 1)
+			out.write(int(data[i]), 8)
+			i++
+		}
+	}
+	return out.data
+}
+
+// LZSSDecompress decompresses heatshrink format data into size bytes
+func LZSSDecompress(data []byte, size int, windowBits int, lookaheadBits int) ([]byte, error) {
+	in := lzssBits{data: data}
+	result := make([]byte, 0, size)
+	for len(result) < size {
+		tag, err := in.read(1)
+		if err != nil {
+			return nil, err
+		}
+		if tag == 1 {
+			v, errLiteral := in.read(8)
+			if errLiteral != nil {
+				return nil, errLiteral
+			}
+			result = append(result, byte(v))
+			continue
+		}
+		offset, errOffset := in.read(windowBits)
+		if errOffset != nil {
+			return nil, errOffset
+		}
+		count, errCount := in.read(lookaheadBits)
+		if errCount != nil {
+			return nil, errCount
+		}
+		offset++
+		for n := count + 1; 0 < n && len(result) < size; n-- {
+			v := byte(0) //heatshrink window is zero filled at start
+			if offset <= len(result) {
+				v = result[len(result)-offset]
+			}
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+// RowXORCodec replaces each row with XOR of it and previous row before coding with Next. Vertical edges and repeating rows turn into zeros
+type RowXORCodec struct {
+	Next Codec
+}
+
+func (p RowXORCodec) Tag() CodecTag { return CODEC_ROWXOR | p.Next.Tag() }
+
+func (p RowXORCodec) Encode(bm *MonoBitmap) []byte {
+	delta := bm.Clone()
+	for y := delta.H - 1; 0 < y; y-- {
+		row, previous := delta.Row(y), delta.Row(y-1)
+		for i := range row {
+			row[i] ^= previous[i]
+		}
+	}
+	return p.Next.Encode(&delta)
+}
+
+func (p RowXORCodec) Decode(data []byte, w int, h int) (MonoBitmap, error) {
+	delta, err := p.Next.Decode(data, w, h)
+	if err != nil {
+		return MonoBitmap{}, err
+	}
+	for y := 1; y < delta.H; y++ {
+		row, previous := delta.Row(y), delta.Row(y-1)
+		for i := range row {
+			row[i] ^= previous[i]
+		}
+	}
+	return delta, nil
+}
+
+// DefaultCodecs returns codecs used by EncodeSmallest when none are given. All use horizontal MSB first packing
+func DefaultCodecs() []Codec {
+	return []Codec{
+		RawCodec{},
+		RLECodec{},
+		PackBitsCodec{},
+		LZSSCodec{},
+		RowXORCodec{Next: PackBitsCodec{}},
+		RowXORCodec{Next: LZSSCodec{}},
+	}
+}
+
+// EncodeSmallest encodes bitmap with all codecs and returns shortest result, tag byte first. Codecs must have unique tags
+func EncodeSmallest(bm *MonoBitmap, codecs []Codec) []byte {
+	if len(codecs) == 0 {
+		codecs = DefaultCodecs()
+	}
+	var best []byte
+	for _, c := range codecs {
+		data := c.Encode(bm)
+		if best == nil || len(data)+1 < len(best) {
+			best = append([]byte{byte(c.Tag())}, data...)
+		}
+	}
+	return best
+}
+
+// DecodeTagged decodes EncodeSmallest output. Codecs must be same that were used on encoding
+func DecodeTagged(data []byte, w int, h int, codecs []Codec) (MonoBitmap, error) {
+	if len(data) == 0 {
+		return MonoBitmap{}, fmt.Errorf("no tagged data")
+	}
+	if len(codecs) == 0 {
+		codecs = DefaultCodecs()
+	}
+	for _, c := range codecs {
+		if c.Tag() == CodecTag(data[0]) {
+			return c.Decode(data[1:], w, h)
+		}
+	}
+	return MonoBitmap{}, fmt.Errorf("no codec for tag %x", data[0])
+}
@@ -0,0 +1,148 @@
+package gomonochromebitmap_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+var testCodecs = map[string]gomonochromebitmap.Codec{
+	"raw":            gomonochromebitmap.RawCodec{},
+	"rle":            gomonochromebitmap.RLECodec{},
+	"packbits":       gomonochromebitmap.PackBitsCodec{},
+	"packbitsPages":  gomonochromebitmap.PackBitsCodec{ByteLayout: gomonochromebitmap.ByteLayout{Packing: gomonochromebitmap.PACK_VERTICAL_PAGES, BitOrder: gomonochromebitmap.LSB_FIRST}},
+	"lzss":           gomonochromebitmap.LZSSCodec{},
+	"lzss10_5":       gomonochromebitmap.LZSSCodec{WindowBits: 10, LookaheadBits: 5},
+	"rowxorPackbits": gomonochromebitmap.RowXORCodec{Next: gomonochromebitmap.PackBitsCodec{}},
+	"rowxorLzss":     gomonochromebitmap.RowXORCodec{Next: gomonochromebitmap.LZSSCodec{}},
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(19))
+	for name, codec := range testCodecs {
+		for _, size := range [][2]int{{1, 1}, {13, 9}, {128, 64}, {300, 17}} {
+			for _, avgRun := range []int{1, 4, 100} {
+				bm := runBitmap(rnd, size[0], size[1], avgRun)
+				data := codec.Encode(&bm)
+				decoded, err := codec.Decode(data, bm.W, bm.H)
+				if err != nil {
+					t.Fatalf("%v %v: %v", name, size, err)
+				}
+				if !equalBitmaps(bm, decoded) {
+					t.Errorf("%v %v run=%v round trip failed", name, size, avgRun)
+				}
+				//Truncated data must give error, not panic
+				if 1 < len(data) && name != "rle" {
+					if _, err := codec.Decode(data[:len(data)/2], bm.W, bm.H); err == nil {
+						t.Errorf("%v %v no error on truncated data", name, size)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestPackBits(t *testing.T) {
+	//Example from Apple technical note TN1023
+	data := []byte{0xAA, 0xAA, 0xAA, 0x80, 0x00, 0x2A, 0xAA, 0xAA, 0xAA, 0xAA, 0x80, 0x00, 0x2A, 0x22, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA, 0xAA}
+	expected := []byte{0xFE, 0xAA, 0x02, 0x80, 0x00, 0x2A, 0xFD, 0xAA, 0x03, 0x80, 0x00, 0x2A, 0x22, 0xF7, 0xAA}
+	packed := gomonochromebitmap.PackBits(data)
+	if !bytes.Equal(packed, expected) {
+		t.Errorf("got %x", packed)
+	}
+	unpacked, err := gomonochromebitmap.UnpackBits(packed, len(data))
+	if err != nil || !bytes.Equal(unpacked, data) {
+		t.Errorf("unpack failed %v", err)
+	}
+	long := bytes.Repeat([]byte{1, 2, 3}, 100)
+	long = append(long, bytes.Repeat([]byte{7}, 300)...)
+	unpacked, err = gomonochromebitmap.UnpackBits(gomonochromebitmap.PackBits(long), len(long))
+	if err != nil || !bytes.Equal(unpacked, long) {
+		t.Errorf("long round trip failed %v", err)
+	}
+}
+
+func TestLZSS(t *testing.T) {
+	//Literal 'a' and back reference offset 1, count 9
+	packed := gomonochromebitmap.LZSSCompress([]byte("aaaaaaaaaa"), 8, 4)
+	if !bytes.Equal(packed, []byte{0xB0, 0x80, 0x20}) {
+		t.Errorf("got %x", packed)
+	}
+	text := []byte("abracadabra abracadabra abracadabra")
+	for _, sizes := range [][2]int{{4, 3}, {8, 4}, {11, 4}, {15, 14}} {
+		unpacked, err := gomonochromebitmap.LZSSDecompress(gomonochromebitmap.LZSSCompress(text, sizes[0], sizes[1]), len(text), sizes[0], sizes[1])
+		if err != nil || !bytes.Equal(unpacked, text) {
+			t.Errorf("%v round trip failed %v", sizes, err)
+		}
+	}
+}
+
+func TestEncodeSmallest(t *testing.T) {
+	rnd := rand.New(rand.NewSource(20))
+	blank := gomonochromebitmap.NewMonoBitmap(128, 64, false)
+	stripes := gomonochromebitmap.NewMonoBitmap(128, 64, false)
+	for x := 0; x < stripes.W; x += 3 {
+		stripes.Vline(x, 0, stripes.H, true)
+	}
+	noise := randomBitmap(rnd, 128, 64)
+	for name, bm := range map[string]gomonochromebitmap.MonoBitmap{"blank": blank, "stripes": stripes, "noise": noise} {
+		data := gomonochromebitmap.EncodeSmallest(&bm, nil)
+		for _, codec := range gomonochromebitmap.DefaultCodecs() {
+			if len(codec.Encode(&bm))+1 < len(data) {
+				t.Errorf("%v: codec %x is smaller than picked %x", name, codec.Tag(), data[0])
+			}
+		}
+		decoded, err := gomonochromebitmap.DecodeTagged(data, bm.W, bm.H, nil)
+		if err != nil || !equalBitmaps(bm, decoded) {
+			t.Errorf("%v: round trip failed %v", name, err)
+		}
+	}
+	if gomonochromebitmap.EncodeSmallest(&stripes, nil)[0] != byte(gomonochromebitmap.CODEC_ROWXOR|gomonochromebitmap.CODEC_PACKBITS) {
+		t.Errorf("repeating rows should pick row XOR")
+	}
+	if _, err := gomonochromebitmap.DecodeTagged([]byte{0x55, 0}, 8, 8, nil); err == nil {
+		t.Errorf("no error on unknown tag")
+	}
+}
+
+// Compression ratios on dog test picture, go test -bench Codec -run XXX
+// threshold: raw 1.000 rle 0.801 packbits 0.784 packbitsPages 0.661 lzss 0.631 rowxorLzss 0.687
+// dithered: all codecs about 1.0, rle 5.392
+// text: rle 0.690 packbits 0.296 packbitsPages 0.285 lzss 0.317 rowxorPackbits 0.307
+func BenchmarkCodecs(b *testing.B) {
+	imgfile, err := os.Open("./testdata/dog.png")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer imgfile.Close()
+	pngimg, errDecode := png.Decode(imgfile)
+	if errDecode != nil {
+		b.Fatal(errDecode)
+	}
+	threshold := gomonochromebitmap.ConvertImage(pngimg, pngimg.Bounds(), gomonochromebitmap.ImageConversion{Method: gomonochromebitmap.THRESHOLD_OTSU})
+	dithered := gomonochromebitmap.ConvertImage(pngimg, pngimg.Bounds(), gomonochromebitmap.ImageConversion{Method: gomonochromebitmap.DITHER_FLOYD_STEINBERG})
+	text := gomonochromebitmap.NewMonoBitmap(128, 64, false)
+	text.Print("Temperature 21.5C\nHumidity 45%\nPressure 1013hPa", gomonochromebitmap.GetFont_5x7(), 7, 1, text.Bounds(), true, true, false, false)
+	text.Fill(image.Rect(0, 50, 127, 63), true)
+
+	inputs := map[string]gomonochromebitmap.MonoBitmap{"threshold": threshold, "dithered": dithered, "text": text}
+	for inputName, bm := range inputs {
+		raw := gomonochromebitmap.PackedSize(bm.W, bm.H, gomonochromebitmap.PACK_HORIZONTAL)
+		for codecName, codec := range testCodecs {
+			b.Run(fmt.Sprintf("%s/%s", inputName, codecName), func(b *testing.B) {
+				var data []byte
+				for range b.N {
+					data = codec.Encode(&bm)
+				}
+				b.ReportMetric(float64(len(data))/float64(raw), "ratio")
+				b.ReportMetric(float64(len(data)), "bytes")
+			})
+		}
+	}
+}
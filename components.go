@@ -0,0 +1,190 @@
+/*
+Connected component labeling
+
+Bitmap is processed as horizontal runs of on pixels. Runs touching runs of
+previous row are joined with union-find, so each pixel is visited only once
+when runs are searched word by word.
+
+Components are numbered in raster order of their first pixel, starting from 1.
+Label 0 is background.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+	"math/bits"
+)
+
+// Connectivity tells which neighbours are connected
+type Connectivity byte
+
+const (
+	CONNECT_4 Connectivity = 4 // Left, right, up and down
+	CONNECT_8 Connectivity = 8 // Also diagonals
+)
+
+// Component is measured blob of connected on pixels
+type Component struct {
+	Label     int
+	Bounds    image.Rectangle // Bounding box
+	Count     int             // Number of pixels
+	CentroidX float64         // Average of pixel coordinates
+	CentroidY float64
+	Mask      MonoBitmap // Pixels of component, size of Bounds. Pixel x,y of mask is pixel Bounds.Min+(x,y)
+}
+
+// pixelRun is run of on pixels x0...x1-1 on row y
+type pixelRun struct {
+	y, x0, x1 int
+}
+
+// rowRuns calls fn for each run of on pixels on row y. End of run x1 is exclusive
+func (p *MonoBitmap) rowRuns(y int, fn func(x0 int, x1 int)) {
+	start := -1
+	for x := 0; x < p.W; x += 32 {
+		n := min(32, p.W-x)
+		word := fetchBits(p.Pix, p.rowBit(y)+x)
+		if n < 32 {
+			word &= 1<<uint32(n) - 1
+		}
+		for k := 0; k < n; {
+			if start < 0 {
+				k += bits.TrailingZeros32(word >> uint32(k))
+				if n <= k {
+					break
+				}
+				start = x + k
+			} else {
+				k += bits.TrailingZeros32(^(word >> uint32(k)))
+				if n <= k {
+					break
+				}
+				fn(start, x+k)
+				start = -1
+			}
+		}
+	}
+	if 0 <= start {
+		fn(start, p.W)
+	}
+}
+
+// labelRuns finds runs and joins them. Returns runs and their component numbers (0...n-1) and number of components
+func (p *MonoBitmap) labelRuns(conn Connectivity) ([]pixelRun, []int, int) {
+	var runs []pixelRun
+	var parent []int
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	reach := 0 //Runs of adjacent rows touch when they overlap, or on 8-connectivity when they touch diagonally
+	if conn == CONNECT_8 {
+		reach = 1
+	}
+
+	prevStart, prevEnd := 0, 0
+	for y := 0; y < p.H; y++ {
+		rowStart := len(runs)
+		j := prevStart
+		p.rowRuns(y, func(x0 int, x1 int) {
+			i := len(runs)
+			runs = append(runs, pixelRun{y: y, x0: x0, x1: x1})
+			parent = append(parent, i)
+			for ; j < prevEnd && runs[j].x1+reach <= x0; j++ { //Skip runs left from this
+			}
+			for k := j; k < prevEnd && runs[k].x0 < x1+reach; k++ {
+				a, b := find(i), find(k)
+				if a != b {
+					parent[max(a, b)] = min(a, b)
+				}
+			}
+		})
+		prevStart, prevEnd = rowStart, len(runs)
+	}
+
+	//Renumber roots in order of appearance. Root is always first run of component
+	labels := make([]int, len(runs))
+	n := 0
+	for i := range runs {
+		if root := find(i); root == i {
+			labels[i] = n
+			n++
+		} else {
+			labels[i] = labels[root]
+		}
+	}
+	return runs, labels, n
+}
+
+// Label returns label of each pixel (index x+W*y) and components
+func (p *MonoBitmap) Label(conn Connectivity) ([]int, []Component) {
+	runs, labels, n := p.labelRuns(conn)
+	result := make([]int, p.W*p.H)
+	for i, r := range runs {
+		for x := r.x0; x < r.x1; x++ {
+			result[r.y*p.W+x] = labels[i] + 1
+		}
+	}
+	return result, measureComponents(runs, labels, n)
+}
+
+// Components finds connected components
+func (p *MonoBitmap) Components(conn Connectivity) []Component {
+	runs, labels, n := p.labelRuns(conn)
+	return measureComponents(runs, labels, n)
+}
+
+func measureComponents(runs []pixelRun, labels []int, n int) []Component {
+	result := make([]Component, n)
+	sumX := make([]int, n)
+	sumY := make([]int, n)
+	for i, r := range runs {
+		c := &result[labels[i]]
+		area := image.Rect(r.x0, r.y, r.x1, r.y+1)
+		if c.Count == 0 {
+			c.Bounds = area
+		} else {
+			c.Bounds = c.Bounds.Union(area)
+		}
+		length := r.x1 - r.x0
+		c.Count += length
+		sumX[labels[i]] += (r.x0 + r.x1 - 1) * length / 2
+		sumY[labels[i]] += r.y * length
+	}
+	for i := range result {
+		c := &result[i]
+		c.Label = i + 1
+		c.CentroidX = float64(sumX[i]) / float64(c.Count)
+		c.CentroidY = float64(sumY[i]) / float64(c.Count)
+		c.Mask = NewMonoBitmap(c.Bounds.Dx(), c.Bounds.Dy(), false)
+	}
+	for i, r := range runs {
+		c := &result[labels[i]]
+		c.Mask.Hline(r.x0-c.Bounds.Min.X, r.x1-1-c.Bounds.Min.X, r.y-c.Bounds.Min.Y, true)
+	}
+	return result
+}
+
+// RemoveSmall clears components having less than minPixels pixels. Returns number of removed components
+func (p *MonoBitmap) RemoveSmall(minPixels int, conn Connectivity) int {
+	runs, labels, n := p.labelRuns(conn)
+	counts := make([]int, n)
+	for i, r := range runs {
+		counts[labels[i]] += r.x1 - r.x0
+	}
+	for i, r := range runs {
+		if counts[labels[i]] < minPixels {
+			p.Hline(r.x0, r.x1-1, r.y, false)
+		}
+	}
+	removed := 0
+	for _, count := range counts {
+		if count < minPixels {
+			removed++
+		}
+	}
+	return removed
+}
@@ -0,0 +1,110 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+// refLabel labels pixels with breadth first search in raster order
+func refLabel(bm gomonochromebitmap.MonoBitmap, diagonal bool) ([]int, int) {
+	labels := make([]int, bm.W*bm.H)
+	n := 0
+	for y := 0; y < bm.H; y++ {
+		for x := 0; x < bm.W; x++ {
+			if !bm.GetPixNoCheck(x, y) || labels[x+bm.W*y] != 0 {
+				continue
+			}
+			n++
+			labels[x+bm.W*y] = n
+			queue := []image.Point{{x, y}}
+			for 0 < len(queue) {
+				q := queue[0]
+				queue = queue[1:]
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if !diagonal && dx != 0 && dy != 0 {
+							continue
+						}
+						a, b := q.X+dx, q.Y+dy
+						if bm.GetPix(a, b) && labels[a+bm.W*b] == 0 {
+							labels[a+bm.W*b] = n
+							queue = append(queue, image.Pt(a, b))
+						}
+					}
+				}
+			}
+		}
+	}
+	return labels, n
+}
+
+func TestLabelReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(33))
+	for i := 0; i < 30; i++ {
+		bm := randomBitmap(rnd, 1+rnd.Intn(100), 1+rnd.Intn(40))
+		for _, conn := range []gomonochromebitmap.Connectivity{gomonochromebitmap.CONNECT_4, gomonochromebitmap.CONNECT_8} {
+			labels, components := bm.Label(conn)
+			expected, n := refLabel(bm, conn == gomonochromebitmap.CONNECT_8)
+			if len(components) != n {
+				t.Fatalf("%v-connectivity found %v components, expected %v", conn, len(components), n)
+			}
+			for j := range labels {
+				if labels[j] != expected[j] {
+					t.Fatalf("%v-connectivity label differs at %v", conn, j)
+				}
+			}
+			total := 0
+			for _, c := range components {
+				total += c.Count
+				if countOn(c.Mask) != c.Count || c.Mask.W != c.Bounds.Dx() || c.Mask.H != c.Bounds.Dy() {
+					t.Fatalf("mask of component %v", c.Label)
+				}
+			}
+			if total != countOn(bm) {
+				t.Errorf("counts %v, on pixels %v", total, countOn(bm))
+			}
+		}
+	}
+}
+
+func TestComponents(t *testing.T) {
+	bm := parseBitmap(
+		"##....#",
+		"##...#.",
+		"....#..",
+		"......#",
+	)
+	four := bm.Components(gomonochromebitmap.CONNECT_4)
+	eight := bm.Components(gomonochromebitmap.CONNECT_8)
+	if len(four) != 5 || len(eight) != 3 {
+		t.Fatalf("found %v and %v components", len(four), len(eight))
+	}
+	square := eight[0]
+	if square.Label != 1 || square.Bounds != image.Rect(0, 0, 2, 2) || square.Count != 4 || square.CentroidX != 0.5 || square.CentroidY != 0.5 {
+		t.Errorf("square %+v", square)
+	}
+	diagonal := eight[1]
+	if diagonal.Bounds != image.Rect(4, 0, 7, 3) || diagonal.Count != 3 || diagonal.CentroidX != 5 || diagonal.CentroidY != 1 {
+		t.Errorf("diagonal %+v", diagonal)
+	}
+	if !equalBitmaps(parseBitmap("..#", ".#.", "#.."), diagonal.Mask) {
+		t.Errorf("mask %v", bitmapString(diagonal.Mask))
+	}
+
+	removed := bm.RemoveSmall(2, gomonochromebitmap.CONNECT_8)
+	expected := parseBitmap(
+		"##....#",
+		"##...#.",
+		"....#..",
+		".......",
+	)
+	if removed != 1 || !equalBitmaps(expected, bm) {
+		t.Errorf("removed %v %v", removed, bitmapString(bm))
+	}
+	if removed = bm.RemoveSmall(4, gomonochromebitmap.CONNECT_4); removed != 3 || countOn(bm) != 4 {
+		t.Errorf("removed %v %v", removed, bitmapString(bm))
+	}
+}
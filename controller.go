@@ -0,0 +1,234 @@
+/*
+Display controller memory layouts
+
+SSD1306, SH1106, ST7565 and PCD8544 store pixels in pages. Page is 8 rows
+high and each byte is one column of page, top pixel is least significant bit.
+Controllers differ on memory width and on column address of first visible
+column.
+
+SHARP memory LCDs (LS013, LS027...) are written line by line. Each line is
+prefixed with its 1-based line address. Bytes are produced as they go on the
+wire, so bit order of line address and pixel data depends on SPI bit order.
+*/
+package gomonochromebitmap
+
+import (
+	"fmt"
+	"image"
+)
+
+// PageLayout describes controller memory having vertical 8 pixel pages
+type PageLayout struct {
+	Columns      int  // Columns on controller memory
+	ColumnOffset int  // Column address of pixel x=0
+	Vertical     bool // Vertical addressing (PCD8544 V=1), all pages of column are written before next column
+}
+
+var (
+	LAYOUT_SSD1306          = PageLayout{Columns: 128}
+	LAYOUT_SH1106           = PageLayout{Columns: 132, ColumnOffset: 2} // 128 pixel wide panel is on middle of 132 columns
+	LAYOUT_ST7565           = PageLayout{Columns: 132}
+	LAYOUT_PCD8544          = PageLayout{Columns: 84}
+	LAYOUT_PCD8544_VERTICAL = PageLayout{Columns: 84, Vertical: true}
+)
+
+// PageChunk is data written from page and column address onwards
+type PageChunk struct {
+	Page   int
+	Column int // Controller column address, ColumnOffset is added
+	Data   []byte
+}
+
+// pageByte returns 8 pixels of column x on page, top pixel is LSB
+func pageByte(bm *MonoBitmap, x int, page int) byte {
+	result := byte(0)
+	for i := 0; i < 8 && page*8+i < bm.H; i++ {
+		if bm.GetPixNoCheck(x, page*8+i) {
+			result |= 1 << uint(i)
+		}
+	}
+	return result
+}
+
+// setPageByte writes 8 pixels of column x on page
+func setPageByte(bm *MonoBitmap, x int, page int, b byte) {
+	for i := 0; i < 8; i++ {
+		bm.SetPix(x, page*8+i, b&(1<<uint(i)) != 0)
+	}
+}
+
+// Export returns data for updating region of bitmap. Region is extended to full pages. Use bm.LocalBounds() for whole screen or bm.DirtyRect() for changed area.
+// With horizontal addressing there is one chunk per page. Vertical addressing writes all pages of columns in one chunk
+func (layout PageLayout) Export(bm *MonoBitmap, region image.Rectangle) []PageChunk {
+	region = region.Intersect(bm.LocalBounds())
+	if region.Empty() {
+		return []PageChunk{}
+	}
+	page0 := region.Min.Y / 8
+	page1 := (region.Max.Y + 7) / 8
+	if layout.Vertical {
+		page0, page1 = 0, (bm.H+7)/8
+		data := make([]byte, 0, region.Dx()*(page1-page0))
+		for x := region.Min.X; x < region.Max.X; x++ {
+			for page := page0; page < page1; page++ {
+				data = append(data, pageByte(bm, x, page))
+			}
+		}
+		return []PageChunk{{Page: page0, Column: layout.ColumnOffset + region.Min.X, Data: data}}
+	}
+	result := make([]PageChunk, 0, page1-page0)
+	for page := page0; page < page1; page++ {
+		data := make([]byte, region.Dx())
+		for i := range data {
+			data[i] = pageByte(bm, region.Min.X+i, page)
+		}
+		result = append(result, PageChunk{Page: page, Column: layout.ColumnOffset + region.Min.X, Data: data})
+	}
+	return result
+}
+
+// Apply writes chunks on bitmap like controller would write them on its memory. Reverse of Export
+func (layout PageLayout) Apply(bm *MonoBitmap, chunks []PageChunk) error {
+	pages := (bm.H + 7) / 8
+	for _, c := range chunks {
+		if c.Page < 0 || pages <= c.Page || c.Column < 0 || layout.Columns <= c.Column {
+			return fmt.Errorf("chunk address page %v column %v out of memory", c.Page, c.Column)
+		}
+		page, column := c.Page, c.Column
+		for _, b := range c.Data {
+			if layout.Columns <= column {
+				return fmt.Errorf("chunk goes over last column")
+			}
+			setPageByte(bm, column-layout.ColumnOffset, page, b)
+			//Address increments like on controller
+			if layout.Vertical {
+				page++
+				if page == pages {
+					page = 0
+					column++
+				}
+			} else {
+				column++
+			}
+		}
+	}
+	return nil
+}
+
+// GRAM returns whole controller memory, pages of Columns bytes. Bitmap is placed at ColumnOffset. Vertical addressing gives column by column order
+func (layout PageLayout) GRAM(bm *MonoBitmap) []byte {
+	pages := (bm.H + 7) / 8
+	result := make([]byte, layout.Columns*pages)
+	for page := 0; page < pages; page++ {
+		for x := 0; x < bm.W && layout.ColumnOffset+x < layout.Columns; x++ {
+			i := page*layout.Columns + layout.ColumnOffset + x
+			if layout.Vertical {
+				i = (layout.ColumnOffset+x)*pages + page
+			}
+			result[i] = pageByte(bm, x, page)
+		}
+	}
+	return result
+}
+
+// ImportGRAM creates w*h bitmap from controller memory dump. Reverse of GRAM
+func (layout PageLayout) ImportGRAM(data []byte, w int, h int) (MonoBitmap, error) {
+	pages := (h + 7) / 8
+	if len(data) < layout.Columns*pages {
+		return MonoBitmap{}, fmt.Errorf("have %v bytes, memory is %v bytes", len(data), layout.Columns*pages)
+	}
+	if layout.Columns < layout.ColumnOffset+w {
+		return MonoBitmap{}, fmt.Errorf("width %v does not fit on %v columns", w, layout.Columns)
+	}
+	result := NewMonoBitmap(w, h, false)
+	for page := 0; page < pages; page++ {
+		for x := 0; x < w; x++ {
+			i := page*layout.Columns + layout.ColumnOffset + x
+			if layout.Vertical {
+				i = (layout.ColumnOffset+x)*pages + page
+			}
+			setPageByte(&result, x, page, data[i])
+		}
+	}
+	return result, nil
+}
+
+const (
+	sharpWrite = 0x80 // M0, data update mode
+	sharpVCOM  = 0x40 // M1
+)
+
+// SharpOptions for SHARP memory LCD
+type SharpOptions struct {
+	VCOM     bool // VCOM bit on command. Must be toggled regularly if display EXTCOMIN is not used
+	LSBFirst bool // SPI sends LSB first. Default is MSB first SPI, then line address bits are reversed
+}
+
+// ExportSharp returns write command updating rows of region. Full rows are sent. On pixel is white (bit 1). Line address is one byte, so up to 255 lines are supported
+func ExportSharp(bm *MonoBitmap, region image.Rectangle, opt SharpOptions) []byte {
+	region = region.Intersect(bm.LocalBounds())
+	if region.Empty() {
+		return []byte{}
+	}
+	rowBytes := (bm.W + 7) / 8
+	result := make([]byte, 0, 2+region.Dy()*(rowBytes+2))
+	cmd := byte(sharpWrite)
+	if opt.VCOM {
+		cmd |= sharpVCOM
+	}
+	order := MSB_FIRST
+	if opt.LSBFirst {
+		cmd = reverseBits(cmd)
+		order = LSB_FIRST
+	}
+	result = append(result, cmd)
+	for y := region.Min.Y; y < region.Max.Y; y++ {
+		address := byte(y + 1)
+		if !opt.LSBFirst {
+			address = reverseBits(address)
+		}
+		result = append(result, address)
+		row := bm.SubBitmap(image.Rect(0, y, bm.W, y+1))
+		result = append(result, row.PackBytes(PACK_HORIZONTAL, order)...)
+		result = append(result, 0) //Dummy byte after each line
+	}
+	return append(result, 0) //Dummy byte at end of transfer
+}
+
+// ImportSharp writes lines of ExportSharp data on bitmap. Reverse of ExportSharp
+func ImportSharp(bm *MonoBitmap, data []byte, opt SharpOptions) error {
+	if len(data) == 0 {
+		return nil
+	}
+	order := MSB_FIRST
+	cmd := data[0]
+	if opt.LSBFirst {
+		order = LSB_FIRST
+		cmd = reverseBits(cmd)
+	}
+	if cmd&sharpWrite == 0 {
+		return fmt.Errorf("not SHARP write command %x", data[0])
+	}
+	rowBytes := (bm.W + 7) / 8
+	data = data[1:]
+	for len(data) >= rowBytes+2 {
+		address := data[0]
+		if !opt.LSBFirst {
+			address = reverseBits(address)
+		}
+		y := int(address) - 1
+		if y < 0 || bm.H <= y {
+			return fmt.Errorf("SHARP line address %v out of display", address)
+		}
+		row, err := UnpackBytes(data[1:1+rowBytes], bm.W, 1, PACK_HORIZONTAL, order)
+		if err != nil {
+			return err
+		}
+		bm.DrawBitmapOp(row, row.LocalBounds(), image.Pt(0, y), ROP_COPY)
+		data = data[rowBytes+2:]
+	}
+	if 1 < len(data) {
+		return fmt.Errorf("SHARP data has %v extra bytes", len(data))
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package gomonochromebitmap_test
+
+import (
+	"bytes"
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+var testLayouts = map[string]gomonochromebitmap.PageLayout{
+	"SSD1306":         gomonochromebitmap.LAYOUT_SSD1306,
+	"SH1106":          gomonochromebitmap.LAYOUT_SH1106,
+	"ST7565":          gomonochromebitmap.LAYOUT_ST7565,
+	"PCD8544":         gomonochromebitmap.LAYOUT_PCD8544,
+	"PCD8544Vertical": gomonochromebitmap.LAYOUT_PCD8544_VERTICAL,
+}
+
+func TestPageLayoutBytes(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(84, 48, false)
+	bm.SetPix(0, 0, true)
+	bm.SetPix(5, 10, true)
+	bm.SetPix(5, 15, true)
+
+	gram := gomonochromebitmap.LAYOUT_SSD1306.GRAM(&bm)
+	if len(gram) != 128*6 || gram[0] != 0x01 || gram[128+5] != 0x84 {
+		t.Errorf("SSD1306 layout failed")
+	}
+	gram = gomonochromebitmap.LAYOUT_SH1106.GRAM(&bm)
+	if gram[0] != 0 || gram[2] != 0x01 || gram[132+7] != 0x84 {
+		t.Errorf("SH1106 column offset failed")
+	}
+	gram = gomonochromebitmap.LAYOUT_PCD8544.GRAM(&bm)
+	if len(gram) != 504 || gram[84+5] != 0x84 {
+		t.Errorf("PCD8544 bank order failed")
+	}
+	gram = gomonochromebitmap.LAYOUT_PCD8544_VERTICAL.GRAM(&bm)
+	if gram[0] != 0x01 || gram[5*6+1] != 0x84 {
+		t.Errorf("PCD8544 vertical order failed")
+	}
+}
+
+func TestPageLayoutRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(23))
+	for name, layout := range testLayouts {
+		w := min(layout.Columns-layout.ColumnOffset, 128)
+		bm := randomBitmap(rnd, w, 45)
+
+		decoded, err := layout.ImportGRAM(layout.GRAM(&bm), bm.W, bm.H)
+		if err != nil || !equalBitmaps(bm, decoded) {
+			t.Errorf("%v GRAM round trip failed %v", name, err)
+		}
+
+		screen := gomonochromebitmap.NewMonoBitmap(bm.W, bm.H, false)
+		if err := layout.Apply(&screen, layout.Export(&bm, bm.Bounds())); err != nil || !equalBitmaps(bm, screen) {
+			t.Errorf("%v export round trip failed %v", name, err)
+		}
+	}
+}
+
+func TestPageLayoutDirtyExport(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(128, 64, false)
+	bm.TrackDirty(true)
+	bm.Fill(image.Rect(10, 12, 20, 17), true)
+
+	chunks := gomonochromebitmap.LAYOUT_SH1106.Export(&bm, bm.DirtyRect())
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 pages, got %v", len(chunks))
+	}
+	for i, c := range chunks {
+		if c.Page != 1+i || c.Column != 12 || len(c.Data) != 11 {
+			t.Errorf("chunk %v page %v column %v len %v", i, c.Page, c.Column, len(c.Data))
+		}
+	}
+	if chunks[0].Data[0] != 0xF0 || chunks[1].Data[0] != 0x03 {
+		t.Errorf("invalid data %x %x", chunks[0].Data, chunks[1].Data)
+	}
+	screen := gomonochromebitmap.NewMonoBitmap(128, 64, false)
+	gomonochromebitmap.LAYOUT_SH1106.Apply(&screen, chunks)
+	if !equalBitmaps(bm, screen) {
+		t.Errorf("dirty update failed")
+	}
+
+	//Vertical addressing sends full columns
+	vertical := gomonochromebitmap.LAYOUT_PCD8544_VERTICAL.Export(&bm, image.Rect(3, 20, 5, 21))
+	if len(vertical) != 1 || vertical[0].Page != 0 || vertical[0].Column != 3 || len(vertical[0].Data) != 2*8 {
+		t.Errorf("vertical export %+v", vertical)
+	}
+
+	if err := gomonochromebitmap.LAYOUT_SSD1306.Apply(&screen, []gomonochromebitmap.PageChunk{{Page: 0, Column: 127, Data: []byte{1, 2}}}); err == nil {
+		t.Errorf("no error on chunk over memory")
+	}
+}
+
+func TestSharp(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(16, 3, false)
+	bm.SetPix(0, 0, true)
+	bm.SetPix(9, 1, true)
+
+	data := gomonochromebitmap.ExportSharp(&bm, image.Rect(0, 0, 16, 2), gomonochromebitmap.SharpOptions{})
+	expected := []byte{0x80, 0x80, 0x80, 0x00, 0x00, 0x40, 0x00, 0x40, 0x00, 0x00}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("MSB first got %x", data)
+	}
+	data = gomonochromebitmap.ExportSharp(&bm, image.Rect(0, 1, 16, 2), gomonochromebitmap.SharpOptions{VCOM: true, LSBFirst: true})
+	expected = []byte{0x03, 0x02, 0x00, 0x02, 0x00, 0x00}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("LSB first got %x", data)
+	}
+
+	rnd := rand.New(rand.NewSource(24))
+	for _, opt := range []gomonochromebitmap.SharpOptions{{}, {VCOM: true}, {LSBFirst: true}} {
+		src := randomBitmap(rnd, 400, 240)
+		screen := gomonochromebitmap.NewMonoBitmap(400, 240, false)
+		if err := gomonochromebitmap.ImportSharp(&screen, gomonochromebitmap.ExportSharp(&src, src.Bounds(), opt), opt); err != nil {
+			t.Fatal(err)
+		}
+		if !equalBitmaps(src, screen) {
+			t.Errorf("%+v round trip failed", opt)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Dirty area tracking
+
+Slow displays are updated only where bitmap has changed. Tracking is off by
+default. When it is on, all drawing functions mark area they touched.
+Tracker is shared by SubBitmap views, so drawing on view marks its parent.
+Pages are 8 row bands like on SSD1306 and ST7565 controllers.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+)
+
+// dirtyTracker collects changed area in root bitmap coordinates
+type dirtyTracker struct {
+	rect  image.Rectangle
+	pages []bool
+}
+
+// TrackDirty turns dirty tracking on or off. Turning on starts with clean bitmap
+func (p *MonoBitmap) TrackDirty(enable bool) {
+	if enable {
+		p.dirty = &dirtyTracker{}
+	} else {
+		p.dirty = nil
+	}
+}
+
+// markDirty adds area r, already inside bitmap, to dirty area
+func (p *MonoBitmap) markDirty(r image.Rectangle) {
+	if p.dirty == nil || r.Empty() {
+		return
+	}
+	r = r.Add(p.Origin)
+	p.dirty.rect = p.dirty.rect.Union(r)
+	for page := r.Min.Y / 8; page <= (r.Max.Y-1)/8; page++ {
+		for len(p.dirty.pages) <= page {
+			p.dirty.pages = append(p.dirty.pages, false)
+		}
+		p.dirty.pages[page] = true
+	}
+}
+
+// MarkDirty marks area as changed. Use for forcing redraw or after writing Pix directly
+func (p *MonoBitmap) MarkDirty(r image.Rectangle) {
+	p.markDirty(r.Intersect(p.LocalBounds()))
+}
+
+// DirtyRect returns bounding box of changed pixels since ClearDirty. Empty if nothing changed or tracking is off
+func (p *MonoBitmap) DirtyRect() image.Rectangle {
+	if p.dirty == nil {
+		return image.Rectangle{}
+	}
+	return p.dirty.rect.Sub(p.Origin).Intersect(p.LocalBounds())
+}
+
+// DirtyPages returns changed flag of each 8 row page. Page n is rows 8n...8n+7 of root bitmap
+func (p *MonoBitmap) DirtyPages() []bool {
+	result := make([]bool, (p.Origin.Y+p.H+7)/8)
+	if p.dirty != nil {
+		copy(result, p.dirty.pages)
+	}
+	return result
+}
+
+// ClearDirty marks whole bitmap clean. Clears also parent and other views sharing tracker
+func (p *MonoBitmap) ClearDirty() {
+	if p.dirty != nil {
+		p.dirty.rect = image.Rectangle{}
+		clear(p.dirty.pages)
+	}
+}
@@ -0,0 +1,120 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestDirtyMutators(t *testing.T) {
+	font := gomonochromebitmap.GetFont_5x7()
+	glyph := font['A']
+	cases := map[string]struct {
+		draw     func(bm *gomonochromebitmap.MonoBitmap)
+		expected image.Rectangle
+	}{
+		"SetPix":     {func(bm *gomonochromebitmap.MonoBitmap) { bm.SetPix(3, 4, true) }, image.Rect(3, 4, 4, 5)},
+		"SetPixOut":  {func(bm *gomonochromebitmap.MonoBitmap) { bm.SetPix(-3, 4, true) }, image.Rectangle{}},
+		"Set":        {func(bm *gomonochromebitmap.MonoBitmap) { bm.Set(3, 4, color.White) }, image.Rect(3, 4, 4, 5)},
+		"Hline":      {func(bm *gomonochromebitmap.MonoBitmap) { bm.Hline(-5, 10, 2, true) }, image.Rect(0, 2, 11, 3)},
+		"Vline":      {func(bm *gomonochromebitmap.MonoBitmap) { bm.Vline(7, 20, 100, false) }, image.Rect(7, 20, 8, 32)},
+		"Fill":       {func(bm *gomonochromebitmap.MonoBitmap) { bm.Fill(image.Rect(10, 10, 20, 15), true) }, image.Rect(10, 10, 21, 16)},
+		"Line":       {func(bm *gomonochromebitmap.MonoBitmap) { bm.Line(image.Pt(40, 3), image.Pt(30, 9), true) }, image.Rect(30, 3, 41, 10)},
+		"Invert":     {func(bm *gomonochromebitmap.MonoBitmap) { bm.Invert(image.Rect(1, 1, 2, 2)) }, image.Rect(1, 1, 3, 3)},
+		"Circle":     {func(bm *gomonochromebitmap.MonoBitmap) { bm.Circle(image.Pt(20, 16), 5, true) }, image.Rect(15, 11, 26, 22)},
+		"CircleFill": {func(bm *gomonochromebitmap.MonoBitmap) { bm.CircleFill(image.Pt(20, 16), 5, true) }, image.Rect(15, 11, 26, 22)},
+		"DrawBitmap": {func(bm *gomonochromebitmap.MonoBitmap) {
+			bm.DrawBitmap(glyph, glyph.Bounds(), image.Pt(60, 28), true, true, false)
+		}, image.Rect(60, 28, 64, 32)},
+		"Print": {func(bm *gomonochromebitmap.MonoBitmap) {
+			bm.Print("A", font, 7, 1, image.Rect(2, 2, 60, 30), true, true, false, false)
+		}, image.Rect(2, 2, 2+glyph.W, 2+glyph.H)},
+		"FlipV": {func(bm *gomonochromebitmap.MonoBitmap) { bm.FlipV() }, image.Rect(0, 0, 64, 32)},
+		"DrawOutside": {func(bm *gomonochromebitmap.MonoBitmap) {
+			bm.DrawBitmap(glyph, glyph.Bounds(), image.Pt(100, 28), true, true, false)
+		}, image.Rectangle{}},
+	}
+	for name, c := range cases {
+		bm := gomonochromebitmap.NewMonoBitmap(64, 32, false)
+		bm.TrackDirty(true)
+		c.draw(&bm)
+		if r := bm.DirtyRect(); r != c.expected && !(r.Empty() && c.expected.Empty()) {
+			t.Errorf("%v: dirty %v expected %v", name, r, c.expected)
+		}
+		bm.ClearDirty()
+		if !bm.DirtyRect().Empty() {
+			t.Errorf("%v: ClearDirty failed", name)
+		}
+	}
+}
+
+func TestDirtyPages(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(128, 64, false)
+	bm.SetPix(0, 0, true)
+	if !bm.DirtyRect().Empty() || len(bm.DirtyPages()) != 8 || bm.DirtyPages()[0] {
+		t.Errorf("tracking should be off by default")
+	}
+
+	bm.TrackDirty(true)
+	bm.SetPix(5, 3, true)
+	bm.Vline(100, 20, 30, true)
+	expected := []bool{true, false, true, true, false, false, false, false}
+	for i, v := range bm.DirtyPages() {
+		if v != expected[i] {
+			t.Errorf("page %v dirty=%v", i, v)
+		}
+	}
+	if bm.DirtyRect() != image.Rect(5, 3, 101, 31) {
+		t.Errorf("dirty rect %v", bm.DirtyRect())
+	}
+
+	bm.ClearDirty()
+	for i, v := range bm.DirtyPages() {
+		if v {
+			t.Errorf("page %v still dirty", i)
+		}
+	}
+	bm.MarkDirty(image.Rect(-10, 60, 10, 100))
+	if bm.DirtyRect() != image.Rect(0, 60, 10, 64) || !bm.DirtyPages()[7] {
+		t.Errorf("MarkDirty failed %v", bm.DirtyRect())
+	}
+
+	bm.TrackDirty(false)
+	bm.SetPix(1, 1, true)
+	if !bm.DirtyRect().Empty() {
+		t.Errorf("tracking not turned off")
+	}
+}
+
+func TestDirtyView(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(128, 64, false)
+	bm.TrackDirty(true)
+	view := bm.SubBitmap(image.Rect(30, 20, 90, 50))
+	view.Hline(0, 5, 2, true)
+	if bm.DirtyRect() != image.Rect(30, 22, 36, 23) {
+		t.Errorf("parent dirty %v", bm.DirtyRect())
+	}
+	if view.DirtyRect() != image.Rect(0, 2, 6, 3) {
+		t.Errorf("view dirty %v", view.DirtyRect())
+	}
+	bm.SetPix(0, 0, true) //Outside of view
+	if view.DirtyRect() != image.Rect(0, 0, 6, 3) {
+		t.Errorf("view dirty should be clipped %v", view.DirtyRect())
+	}
+
+	clone := view.Clone()
+	clone.SetPix(10, 10, true)
+	bm.ClearDirty()
+	view.ClearDirty()
+	clone.SetPix(11, 11, true)
+	if !bm.DirtyRect().Empty() || !clone.DirtyRect().Empty() {
+		t.Errorf("clone should not share tracking")
+	}
+
+	bm.Rotate90(1)
+	if bm.DirtyRect() != bm.Bounds() {
+		t.Errorf("rotate dirty %v", bm.DirtyRect())
+	}
+}
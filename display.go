@@ -0,0 +1,242 @@
+/*
+Display drivers
+
+Display is implemented by drivers that send bitmap to panel. Drivers
+generate controller commands onto DisplayBus, so same driver works over I2C,
+SPI or on FakePanel in tests.
+
+SSD1306 (and SH1106 with LAYOUT_SH1106) and ST7565 are updated using page
+addressing. Set page and column address, then write page bytes.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+	"io"
+)
+
+// Display is monochrome panel
+type Display interface {
+	Bounds() image.Rectangle
+	Flush(bm *MonoBitmap, region image.Rectangle) error // Sends region of bitmap, usually bm.LocalBounds() or bm.DirtyRect(). Region is extended to full pages
+	SetContrast(level byte) error
+	Power(on bool) error
+	Invert(inverted bool) error
+}
+
+// DisplayBus sends commands and pixel data to controller. On SPI it is D/C pin, on I2C it is control byte
+type DisplayBus interface {
+	WriteCommand(cmd []byte) error
+	WriteData(data []byte) error
+}
+
+// I2CTransactor is I2C device. Tx writes w and then reads r on one transaction. Compatible with periph.io conn.Conn
+type I2CTransactor interface {
+	Tx(w []byte, r []byte) error
+}
+
+const (
+	i2cControlCommand = 0x00 // Co=0 D/C=0, rest of transaction is commands
+	i2cControlData    = 0x40 // Co=0 D/C=1, rest of transaction is data
+)
+
+// I2CBus frames commands and data with control byte, like on SSD1306 and ST7567 I2C interface
+type I2CBus struct {
+	tx          func(w []byte) error
+	MaxTransfer int // Maximum data bytes on one transaction, 0 is unlimited
+}
+
+// NewI2CBus creates bus on I2C device
+func NewI2CBus(dev I2CTransactor) *I2CBus {
+	return &I2CBus{tx: func(w []byte) error { return dev.Tx(w, nil) }}
+}
+
+// NewI2CWriterBus creates bus on writer where each Write is one transaction, like /dev/i2c-N after I2C_SLAVE ioctl
+func NewI2CWriterBus(w io.Writer) *I2CBus {
+	return &I2CBus{tx: func(b []byte) error {
+		_, err := w.Write(b)
+		return err
+	}}
+}
+
+func (p *I2CBus) WriteCommand(cmd []byte) error {
+	return p.tx(append([]byte{i2cControlCommand}, cmd...))
+}
+
+func (p *I2CBus) WriteData(data []byte) error {
+	for 0 < len(data) {
+		n := len(data)
+		if 0 < p.MaxTransfer {
+			n = min(n, p.MaxTransfer)
+		}
+		if err := p.tx(append([]byte{i2cControlData}, data[:n]...)); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// Controller commands. Most are common to SSD1306, SH1106 and ST7565
+const (
+	CMD_DISPLAY_OFF   = 0xAE
+	CMD_DISPLAY_ON    = 0xAF
+	CMD_NORMAL        = 0xA6
+	CMD_INVERSE       = 0xA7
+	CMD_CONTRAST      = 0x81 // Followed by level
+	CMD_PAGE_ADDRESS  = 0xB0 // Page number on lowest bits
+	CMD_COLUMN_HIGH   = 0x10 // High nibble of column address on lowest bits
+	CMD_COLUMN_LOW    = 0x00 // Low nibble of column address on lowest bits
+	CMD_START_LINE    = 0x40 // Line number on lowest bits
+	CMD_SEGMENT_REMAP = 0xA1
+	CMD_COM_SCAN_DEC  = 0xC8
+	CMD_ALL_ON_RESUME = 0xA4
+	CMD_ADDRESSING    = 0x20 // SSD1306 only, followed by mode. 0 horizontal, 1 vertical, 2 page
+	CMD_COLUMN_WINDOW = 0x21 // SSD1306 only, followed by start and end column
+	CMD_PAGE_WINDOW   = 0x22 // SSD1306 only, followed by start and end page
+	CMD_CHARGE_PUMP   = 0x8D // SSD1306 only, followed by 0x14 enable or 0x10 disable
+	CMD_MULTIPLEX     = 0xA8 // SSD1306 and SH1106, followed by height-1
+	CMD_COM_PINS      = 0xDA // SSD1306 and SH1106, followed by configuration
+	CMD_SH1106_DCDC   = 0xAD // SH1106 only, followed by 0x8B enable or 0x8A disable
+	CMD_ST7565_RESET  = 0xE2
+	CMD_ST7565_BIAS_9 = 0xA2
+	CMD_ST7565_POWER  = 0x28 // Booster, regulator and follower bits on lowest bits
+	CMD_ST7565_RATIO  = 0x20 // Regulator resistor ratio on lowest bits
+)
+
+// pageDisplay is common part of page addressed controllers
+type pageDisplay struct {
+	bus    DisplayBus
+	layout PageLayout
+	w      int
+	h      int
+}
+
+func (p *pageDisplay) Bounds() image.Rectangle {
+	return image.Rect(0, 0, p.w, p.h)
+}
+
+// Flush sends pages of region. Bitmap corner is panel corner
+func (p *pageDisplay) Flush(bm *MonoBitmap, region image.Rectangle) error {
+	view := bm.SubBitmap(p.Bounds())
+	for _, chunk := range p.layout.Export(&view, region.Intersect(view.LocalBounds())) {
+		cmd := []byte{
+			CMD_PAGE_ADDRESS | byte(chunk.Page),
+			CMD_COLUMN_HIGH | byte(chunk.Column>>4),
+			CMD_COLUMN_LOW | byte(chunk.Column&0x0F),
+		}
+		if err := p.bus.WriteCommand(cmd); err != nil {
+			return err
+		}
+		if err := p.bus.WriteData(chunk.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *pageDisplay) Invert(inverted bool) error {
+	if inverted {
+		return p.bus.WriteCommand([]byte{CMD_INVERSE})
+	}
+	return p.bus.WriteCommand([]byte{CMD_NORMAL})
+}
+
+// SSD1306 driver. Works also with SH1106 when layout is LAYOUT_SH1106, then SH1106 DC-DC commands are used instead of charge pump
+type SSD1306 struct {
+	pageDisplay
+	sh1106 bool
+}
+
+// NewSSD1306 creates driver for w*h panel. Layout is LAYOUT_SSD1306 or LAYOUT_SH1106. Call Init before use
+func NewSSD1306(bus DisplayBus, w int, h int, layout PageLayout) *SSD1306 {
+	return &SSD1306{pageDisplay{bus: bus, layout: layout, w: w, h: h}, layout == LAYOUT_SH1106}
+}
+
+// supply returns commands that turn charge pump (SH1106: DC-DC converter) on or off
+func (p *SSD1306) supply(on bool) []byte {
+	switch {
+	case p.sh1106 && on:
+		return []byte{CMD_SH1106_DCDC, 0x8B}
+	case p.sh1106:
+		return []byte{CMD_SH1106_DCDC, 0x8A}
+	case on:
+		return []byte{CMD_CHARGE_PUMP, 0x14}
+	}
+	return []byte{CMD_CHARGE_PUMP, 0x10}
+}
+
+// Init sends initialization sequence with charge pump on and page addressing. SH1106 has only page addressing
+func (p *SSD1306) Init() error {
+	comPins := byte(0x12)
+	if p.h <= 32 {
+		comPins = 0x02
+	}
+	cmd := []byte{
+		CMD_DISPLAY_OFF,
+		CMD_MULTIPLEX, byte(p.h - 1),
+		CMD_START_LINE,
+	}
+	cmd = append(cmd, p.supply(true)...)
+	if !p.sh1106 {
+		cmd = append(cmd, CMD_ADDRESSING, 0x02)
+	}
+	return p.bus.WriteCommand(append(cmd,
+		CMD_SEGMENT_REMAP,
+		CMD_COM_SCAN_DEC,
+		CMD_COM_PINS, comPins,
+		CMD_CONTRAST, 0x7F,
+		CMD_ALL_ON_RESUME,
+		CMD_NORMAL,
+		CMD_DISPLAY_ON,
+	))
+}
+
+func (p *SSD1306) SetContrast(level byte) error {
+	return p.bus.WriteCommand([]byte{CMD_CONTRAST, level})
+}
+
+func (p *SSD1306) Power(on bool) error {
+	if on {
+		return p.bus.WriteCommand(append(p.supply(true), CMD_DISPLAY_ON))
+	}
+	return p.bus.WriteCommand(append([]byte{CMD_DISPLAY_OFF}, p.supply(false)...))
+}
+
+// ST7565 driver
+type ST7565 struct {
+	pageDisplay
+}
+
+// NewST7565 creates driver for w*h panel. Modules with reversed segments need ColumnOffset 4 on layout. Call Init before use
+func NewST7565(bus DisplayBus, w int, h int, layout PageLayout) *ST7565 {
+	return &ST7565{pageDisplay{bus: bus, layout: layout, w: w, h: h}}
+}
+
+// Init resets controller and turns on internal power supply
+func (p *ST7565) Init() error {
+	return p.bus.WriteCommand([]byte{
+		CMD_ST7565_RESET,
+		CMD_ST7565_BIAS_9,
+		CMD_COM_SCAN_DEC,
+		CMD_START_LINE,
+		CMD_ST7565_POWER | 0x07,
+		CMD_ST7565_RATIO | 0x05,
+		CMD_CONTRAST, 0x20,
+		CMD_ALL_ON_RESUME,
+		CMD_NORMAL,
+		CMD_DISPLAY_ON,
+	})
+}
+
+// SetContrast sets electronic volume, 0-63
+func (p *ST7565) SetContrast(level byte) error {
+	return p.bus.WriteCommand([]byte{CMD_CONTRAST, min(level, 63)})
+}
+
+func (p *ST7565) Power(on bool) error {
+	if on {
+		return p.bus.WriteCommand([]byte{CMD_ST7565_POWER | 0x07, CMD_DISPLAY_ON})
+	}
+	return p.bus.WriteCommand([]byte{CMD_DISPLAY_OFF, CMD_ST7565_POWER})
+}
@@ -0,0 +1,135 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestSSD1306Driver(t *testing.T) {
+	panel := gomonochromebitmap.NewFakePanel(gomonochromebitmap.LAYOUT_SSD1306, 128, 64, false)
+	bus := gomonochromebitmap.NewI2CBus(panel)
+	bus.MaxTransfer = 32
+	ssd := gomonochromebitmap.NewSSD1306(bus, 128, 64, gomonochromebitmap.LAYOUT_SSD1306)
+	var display gomonochromebitmap.Display = ssd
+	if err := ssd.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if !panel.On || panel.Inverted {
+		t.Errorf("init failed")
+	}
+
+	bm := randomBitmap(rand.New(rand.NewSource(25)), 128, 64)
+	if err := display.Flush(&bm, display.Bounds()); err != nil {
+		t.Fatal(err)
+	}
+	if screen := panel.Bitmap(); !equalBitmaps(bm, screen) {
+		t.Errorf("full flush failed")
+	}
+	for _, tr := range panel.Transactions {
+		if 33 < len(tr) {
+			t.Errorf("transaction of %v bytes", len(tr))
+		}
+	}
+
+	//Only dirty pages are sent
+	bm.TrackDirty(true)
+	bm.Fill(image.Rect(60, 20, 70, 22), false)
+	panel.Transactions = nil
+	display.Flush(&bm, bm.DirtyRect())
+	sent := 0
+	for _, tr := range panel.Transactions {
+		if tr[0] == 0x40 {
+			sent += len(tr) - 1
+		}
+	}
+	if sent != 11 {
+		t.Errorf("sent %v data bytes for dirty page", sent)
+	}
+	if screen := panel.Bitmap(); !equalBitmaps(bm, screen) {
+		t.Errorf("dirty flush failed")
+	}
+
+	display.SetContrast(200)
+	display.Invert(true)
+	display.Power(false)
+	if panel.Contrast != 200 || !panel.Inverted || panel.On {
+		t.Errorf("settings failed %+v", panel)
+	}
+}
+
+func TestSH1106Driver(t *testing.T) {
+	panel := gomonochromebitmap.NewFakePanel(gomonochromebitmap.LAYOUT_SH1106, 128, 64, false)
+	ssd := gomonochromebitmap.NewSSD1306(gomonochromebitmap.NewI2CWriterBus(panel), 128, 64, gomonochromebitmap.LAYOUT_SH1106)
+	bm := gomonochromebitmap.NewMonoBitmap(128, 64, false)
+	bm.SetPix(0, 0, true)
+	bm.SetPix(127, 63, true)
+	ssd.Flush(&bm, bm.Bounds())
+	if panel.GRAM[2] != 0x01 || panel.GRAM[7*132+129] != 0x80 || panel.GRAM[0] != 0 {
+		t.Errorf("column offset failed")
+	}
+	if screen := panel.Bitmap(); !equalBitmaps(bm, screen) {
+		t.Errorf("flush failed")
+	}
+
+	//SH1106 has DC-DC converter instead of charge pump and no addressing mode command
+	for _, step := range []struct {
+		name     string
+		fn       func() error
+		expected []byte
+	}{
+		{"init", ssd.Init, []byte{0xAE, 0xA8, 63, 0x40, 0xAD, 0x8B, 0xA1, 0xC8, 0xDA, 0x12, 0x81, 0x7F, 0xA4, 0xA6, 0xAF}},
+		{"power off", func() error { return ssd.Power(false) }, []byte{0xAE, 0xAD, 0x8A}},
+		{"power on", func() error { return ssd.Power(true) }, []byte{0xAD, 0x8B, 0xAF}},
+	} {
+		panel.Transactions = nil
+		if err := step.fn(); err != nil {
+			t.Fatal(err)
+		}
+		if len(panel.Transactions) != 1 || string(panel.Transactions[0][1:]) != string(step.expected) {
+			t.Errorf("%v sent %X", step.name, panel.Transactions)
+		}
+	}
+	if !panel.On {
+		t.Errorf("power on failed")
+	}
+}
+
+func TestST7565Driver(t *testing.T) {
+	panel := gomonochromebitmap.NewFakePanel(gomonochromebitmap.LAYOUT_ST7565, 128, 64, true)
+	st := gomonochromebitmap.NewST7565(panel, 128, 64, gomonochromebitmap.LAYOUT_ST7565)
+	if err := st.Init(); err != nil {
+		t.Fatal(err)
+	}
+	//Bitmap larger than display is clipped
+	bm := randomBitmap(rand.New(rand.NewSource(26)), 200, 100)
+	st.Flush(&bm, bm.Bounds())
+	if screen := panel.Bitmap(); !equalBitmaps(bm.SubBitmap(st.Bounds()), screen) {
+		t.Errorf("flush failed")
+	}
+	st.SetContrast(100)
+	if !panel.On || panel.Contrast != 63 {
+		t.Errorf("contrast %v", panel.Contrast)
+	}
+}
+
+func TestFakePanelAddressing(t *testing.T) {
+	panel := gomonochromebitmap.NewFakePanel(gomonochromebitmap.LAYOUT_SSD1306, 128, 32, false)
+	bm := randomBitmap(rand.New(rand.NewSource(27)), 128, 32)
+
+	//Horizontal addressing over whole screen and Co bit framed commands
+	panel.Write([]byte{0x00, 0x20, 0x00, 0x21, 0, 127, 0x22, 0, 3})
+	panel.Write(append([]byte{0x40}, gomonochromebitmap.LAYOUT_SSD1306.GRAM(&bm)...))
+	panel.Tx([]byte{0x80, 0xAF, 0x80, 0xA7}, nil)
+	if screen := panel.Bitmap(); !equalBitmaps(bm, screen) {
+		t.Errorf("horizontal addressing failed")
+	}
+	if !panel.On || !panel.Inverted || len(panel.Transactions) != 3 {
+		t.Errorf("Co bit commands failed")
+	}
+	if _, err := panel.Write([]byte{0x01}); err == nil {
+		t.Errorf("no error on invalid control byte")
+	}
+}
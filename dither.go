@@ -0,0 +1,308 @@
+/*
+Image to bitmap conversion with dithering
+
+Photos look terrible with plain threshold. Error diffusion (Floyd-Steinberg etc...)
+spreads quantization error to neighbour pixels, ordered dithering (Bayer, blue noise)
+compares each pixel against tiled threshold map.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// ConversionMethod selects how gray levels are turned into on/off pixels
+type ConversionMethod byte
+
+const (
+	CONVERT_THRESHOLD          ConversionMethod = 0 // on if level > Threshold
+	DITHER_FLOYD_STEINBERG     ConversionMethod = 1
+	DITHER_ATKINSON            ConversionMethod = 2
+	DITHER_JARVIS_JUDICE_NINKE ConversionMethod = 3
+	DITHER_STUCKI              ConversionMethod = 4
+	DITHER_SIERRA              ConversionMethod = 5
+	DITHER_BAYER2              ConversionMethod = 6
+	DITHER_BAYER4              ConversionMethod = 7
+	DITHER_BAYER8              ConversionMethod = 8
+	DITHER_BLUE_NOISE          ConversionMethod = 9
+	THRESHOLD_OTSU             ConversionMethod = 10 // global threshold picked from histogram
+	THRESHOLD_MEAN             ConversionMethod = 11 // local mean of Window minus Offset
+	THRESHOLD_GAUSSIAN         ConversionMethod = 12 // gaussian weighted local mean of Window minus Offset
+	THRESHOLD_NIBLACK          ConversionMethod = 13 // local mean + K*deviation
+	THRESHOLD_SAUVOLA          ConversionMethod = 14 // local mean * (1 + K*(deviation/128 - 1))
+)
+
+// LumaMode selects how color is turned into gray level
+type LumaMode byte
+
+const (
+	LUMA_MAX_CHANNEL LumaMode = 0 // max(R,G,B) like NewMonoBitmapFromImage
+	LUMA_REC601      LumaMode = 1 // 0.299R + 0.587G + 0.114B
+	LUMA_REC709      LumaMode = 2 // 0.2126R + 0.7152G + 0.0722B
+)
+
+// ImageConversion settings for ConvertImage
+type ImageConversion struct {
+	Method     ConversionMethod
+	Luma       LumaMode
+	Threshold  byte //Used only with CONVERT_THRESHOLD
+	Invert     bool //Dark pixels are on
+	Serpentine bool //Error diffusion runs every other row from right to left. Reduces worm artifacts
+
+	//Adaptive thresholding options
+	Window int     //Size of local window in pixels. 0 = 15
+	K      float64 //Niblack and Sauvola weight for deviation. 0 = -0.2 on Niblack, 0.34 on Sauvola
+	Offset float64 //Subtracted from local mean on THRESHOLD_MEAN and THRESHOLD_GAUSSIAN
+}
+
+// ConvertImage creates bitmap from area of image. Bright pixels are on unless Invert is set. Use OtsuThreshold for checking threshold that THRESHOLD_OTSU picks
+func ConvertImage(img image.Image, area image.Rectangle, settings ImageConversion) MonoBitmap {
+	area = area.Intersect(img.Bounds())
+	levels := grayLevels(img, area, settings.Luma)
+	w := area.Dx()
+	h := area.Dy()
+	result := NewMonoBitmap(w, h, false)
+
+	switch settings.Method {
+	case DITHER_FLOYD_STEINBERG, DITHER_ATKINSON, DITHER_JARVIS_JUDICE_NINKE, DITHER_STUCKI, DITHER_SIERRA:
+		errorDiffusion(&result, levels, diffusionKernels[settings.Method], settings.Serpentine)
+	case DITHER_BAYER2:
+		orderedDither(&result, levels, bayerMatrix(2), 2)
+	case DITHER_BAYER4:
+		orderedDither(&result, levels, bayerMatrix(4), 4)
+	case DITHER_BAYER8:
+		orderedDither(&result, levels, bayerMatrix(8), 8)
+	case DITHER_BLUE_NOISE:
+		orderedDither(&result, levels, blueNoiseMatrix(), blueNoiseSize)
+	case THRESHOLD_MEAN, THRESHOLD_GAUSSIAN, THRESHOLD_NIBLACK, THRESHOLD_SAUVOLA:
+		adaptiveThreshold(&result, levels, settings)
+	default:
+		threshold := settings.Threshold
+		if settings.Method == THRESHOLD_OTSU {
+			threshold = otsu(levels)
+		}
+		t := float32(threshold)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				result.SetPixNoCheck(x, y, t < levels[x+y*w])
+			}
+		}
+	}
+	if settings.Invert {
+		result.Invert(result.LocalBounds())
+	}
+	return result
+}
+
+// grayLevels returns 0-255 gray levels of area, row by row
+func grayLevels(img image.Image, area image.Rectangle, luma LumaMode) []float32 {
+	w := area.Dx()
+	result := make([]float32, w*area.Dy())
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			var v float32
+			switch luma {
+			case LUMA_REC601:
+				v = 0.299*float32(r) + 0.587*float32(g) + 0.114*float32(b)
+			case LUMA_REC709:
+				v = 0.2126*float32(r) + 0.7152*float32(g) + 0.0722*float32(b)
+			default:
+				v = float32(max(r, g, b)>>8) * 257 //Same rounding than NewMonoBitmapFromImage has
+			}
+			result[(x-area.Min.X)+(y-area.Min.Y)*w] = v / 257
+		}
+	}
+	return result
+}
+
+// diffusionTap tells how big share of error goes to pixel at offset dx,dy
+type diffusionTap struct {
+	dx, dy int
+	weight float32
+}
+
+func newDiffusionKernel(divisor float32, taps ...diffusionTap) []diffusionTap {
+	for i := range taps {
+		taps[i].weight /= divisor
+	}
+	return taps
+}
+
+var diffusionKernels = map[ConversionMethod][]diffusionTap{
+	DITHER_FLOYD_STEINBERG: newDiffusionKernel(16,
+		diffusionTap{1, 0, 7},
+		diffusionTap{-1, 1, 3}, diffusionTap{0, 1, 5}, diffusionTap{1, 1, 1}),
+	DITHER_ATKINSON: newDiffusionKernel(8, //Diffuses only 3/4 of error
+		diffusionTap{1, 0, 1}, diffusionTap{2, 0, 1},
+		diffusionTap{-1, 1, 1}, diffusionTap{0, 1, 1}, diffusionTap{1, 1, 1},
+		diffusionTap{0, 2, 1}),
+	DITHER_JARVIS_JUDICE_NINKE: newDiffusionKernel(48,
+		diffusionTap{1, 0, 7}, diffusionTap{2, 0, 5},
+		diffusionTap{-2, 1, 3}, diffusionTap{-1, 1, 5}, diffusionTap{0, 1, 7}, diffusionTap{1, 1, 5}, diffusionTap{2, 1, 3},
+		diffusionTap{-2, 2, 1}, diffusionTap{-1, 2, 3}, diffusionTap{0, 2, 5}, diffusionTap{1, 2, 3}, diffusionTap{2, 2, 1}),
+	DITHER_STUCKI: newDiffusionKernel(42,
+		diffusionTap{1, 0, 8}, diffusionTap{2, 0, 4},
+		diffusionTap{-2, 1, 2}, diffusionTap{-1, 1, 4}, diffusionTap{0, 1, 8}, diffusionTap{1, 1, 4}, diffusionTap{2, 1, 2},
+		diffusionTap{-2, 2, 1}, diffusionTap{-1, 2, 2}, diffusionTap{0, 2, 4}, diffusionTap{1, 2, 2}, diffusionTap{2, 2, 1}),
+	DITHER_SIERRA: newDiffusionKernel(32,
+		diffusionTap{1, 0, 5}, diffusionTap{2, 0, 3},
+		diffusionTap{-2, 1, 2}, diffusionTap{-1, 1, 4}, diffusionTap{0, 1, 5}, diffusionTap{1, 1, 4}, diffusionTap{2, 1, 2},
+		diffusionTap{-1, 2, 2}, diffusionTap{0, 2, 3}, diffusionTap{1, 2, 2}),
+}
+
+// errorDiffusion quantizes levels on result. Levels are modified
+func errorDiffusion(result *MonoBitmap, levels []float32, kernel []diffusionTap, serpentine bool) {
+	w := result.W
+	h := result.H
+	for y := 0; y < h; y++ {
+		dir := 1
+		x := 0
+		if serpentine && y%2 == 1 {
+			dir = -1
+			x = w - 1
+		}
+		for ; 0 <= x && x < w; x += dir {
+			old := levels[x+y*w]
+			on := 127.5 < old
+			quantErr := old
+			if on {
+				quantErr = old - 255
+			}
+			result.SetPixNoCheck(x, y, on)
+			for _, tap := range kernel {
+				tx := x + tap.dx*dir
+				ty := y + tap.dy
+				if 0 <= tx && tx < w && ty < h {
+					levels[tx+ty*w] += quantErr * tap.weight
+				}
+			}
+		}
+	}
+}
+
+// orderedDither compares levels against tiled n*n threshold map. Map values are ranks 0..n*n-1
+func orderedDither(result *MonoBitmap, levels []float32, matrix []int, n int) {
+	scale := 255 / float32(n*n)
+	for y := 0; y < result.H; y++ {
+		for x := 0; x < result.W; x++ {
+			t := (float32(matrix[x%n+(y%n)*n]) + 0.5) * scale
+			result.SetPixNoCheck(x, y, t < levels[x+y*result.W])
+		}
+	}
+}
+
+// bayerMatrix generates n*n (n is power of two) Bayer index matrix recursively
+func bayerMatrix(n int) []int {
+	if n <= 1 {
+		return []int{0}
+	}
+	half := n / 2
+	prev := bayerMatrix(half)
+	result := make([]int, n*n)
+	offsets := [4]int{0, 2, 3, 1} //Quadrant order: top left, top right, bottom left, bottom right
+	for q := 0; q < 4; q++ {
+		qx := (q % 2) * half
+		qy := (q / 2) * half
+		for y := 0; y < half; y++ {
+			for x := 0; x < half; x++ {
+				result[qx+x+(qy+y)*n] = 4*prev[x+y*half] + offsets[q]
+			}
+		}
+	}
+	return result
+}
+
+const blueNoiseSize = 32
+
+var (
+	blueNoiseOnce sync.Once
+	blueNoiseMap  []int
+)
+
+// blueNoiseMatrix returns blueNoiseSize*blueNoiseSize rank matrix. Generated on first use with void-and-cluster method
+func blueNoiseMatrix() []int {
+	blueNoiseOnce.Do(func() {
+		blueNoiseMap = voidAndCluster(blueNoiseSize, 1.5, rand.New(rand.NewSource(1)))
+	})
+	return blueNoiseMap
+}
+
+// voidAndCluster generates n*n threshold map (Ulichney 1993) on torus with gaussian filter
+func voidAndCluster(n int, sigma float64, rnd *rand.Rand) []int {
+	total := n * n
+	//Gaussian weights by wrapped offset
+	gauss := make([]float64, total)
+	for dy := 0; dy < n; dy++ {
+		for dx := 0; dx < n; dx++ {
+			x := float64(min(dx, n-dx))
+			y := float64(min(dy, n-dy))
+			gauss[dx+dy*n] = math.Exp(-(x*x + y*y) / (2 * sigma * sigma))
+		}
+	}
+
+	pattern := make([]bool, total)
+	energy := make([]float64, total)
+	toggle := func(pattern []bool, energy []float64, i int) {
+		pattern[i] = !pattern[i]
+		sign := 1.0
+		if !pattern[i] {
+			sign = -1
+		}
+		ix, iy := i%n, i/n
+		for y := 0; y < n; y++ {
+			row := ((y - iy + n) % n) * n
+			for x := 0; x < n; x++ {
+				energy[x+y*n] += sign * gauss[(x-ix+n)%n+row]
+			}
+		}
+	}
+	// find returns index of pixel with value v having highest (tightest cluster) or lowest (largest void) energy
+	find := func(pattern []bool, energy []float64, v bool, highest bool) int {
+		best := -1
+		for i := range pattern {
+			if pattern[i] != v {
+				continue
+			}
+			if best < 0 || (highest && energy[best] < energy[i]) || (!highest && energy[i] < energy[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+
+	//Initial random pattern, then relax it until removing tightest cluster and filling largest void hits same pixel
+	ones := total / 10
+	for _, i := range rnd.Perm(total)[:ones] {
+		toggle(pattern, energy, i)
+	}
+	for {
+		cluster := find(pattern, energy, true, true)
+		toggle(pattern, energy, cluster)
+		void := find(pattern, energy, false, false)
+		toggle(pattern, energy, void)
+		if void == cluster {
+			break
+		}
+	}
+
+	result := make([]int, total)
+	//Phase 1: rank initial ones by removing tightest clusters
+	work := append([]bool{}, pattern...)
+	workEnergy := append([]float64{}, energy...)
+	for rank := ones - 1; 0 <= rank; rank-- {
+		cluster := find(work, workEnergy, true, true)
+		toggle(work, workEnergy, cluster)
+		result[cluster] = rank
+	}
+	//Phase 2 and 3: fill largest voids
+	for rank := ones; rank < total; rank++ {
+		void := find(pattern, energy, false, false)
+		toggle(pattern, energy, void)
+		result[void] = rank
+	}
+	return result
+}
@@ -0,0 +1,106 @@
+package gomonochromebitmap_test
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+var conversionMethods = map[string]gomonochromebitmap.ConversionMethod{
+	"floydsteinberg": gomonochromebitmap.DITHER_FLOYD_STEINBERG,
+	"atkinson":       gomonochromebitmap.DITHER_ATKINSON,
+	"jjn":            gomonochromebitmap.DITHER_JARVIS_JUDICE_NINKE,
+	"stucki":         gomonochromebitmap.DITHER_STUCKI,
+	"sierra":         gomonochromebitmap.DITHER_SIERRA,
+	"bayer2":         gomonochromebitmap.DITHER_BAYER2,
+	"bayer4":         gomonochromebitmap.DITHER_BAYER4,
+	"bayer8":         gomonochromebitmap.DITHER_BAYER8,
+	"bluenoise":      gomonochromebitmap.DITHER_BLUE_NOISE,
+}
+
+func countOn(bm gomonochromebitmap.MonoBitmap) int {
+	result := 0
+	for y := 0; y < bm.H; y++ {
+		for x := 0; x < bm.W; x++ {
+			if bm.GetPixNoCheck(x, y) {
+				result++
+			}
+		}
+	}
+	return result
+}
+
+// Flat gray must produce about same pixel density
+func TestDitherDensity(t *testing.T) {
+	for _, level := range []uint8{32, 64, 128, 192} {
+		img := image.NewUniform(color.Gray{Y: level})
+		for name, method := range conversionMethods {
+			bm := gomonochromebitmap.ConvertImage(img, image.Rect(0, 0, 64, 64), gomonochromebitmap.ImageConversion{Method: method, Luma: gomonochromebitmap.LUMA_REC601})
+			density := float64(countOn(bm)) / float64(bm.W*bm.H)
+			tolerance := 0.04
+			if method == gomonochromebitmap.DITHER_BAYER2 {
+				tolerance = 0.13 //only 5 levels
+			}
+			if method == gomonochromebitmap.DITHER_ATKINSON {
+				tolerance = 0.13 //loses part of error, shadows and highlights get clipped
+			}
+			if math.Abs(density-float64(level)/255) > tolerance {
+				t.Errorf("%s level %v produced density %.3f", name, level, density)
+			}
+		}
+	}
+}
+
+func TestConvertImageArea(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 20, 10))
+	img.SetGray(12, 4, color.Gray{Y: 255})
+	bm := gomonochromebitmap.ConvertImage(img, image.Rect(10, 2, 30, 8), gomonochromebitmap.ImageConversion{Threshold: 128})
+	if bm.W != 10 || bm.H != 6 {
+		t.Fatalf("area not clipped, got %vx%v", bm.W, bm.H)
+	}
+	if countOn(bm) != 1 || !bm.GetPix(2, 2) {
+		t.Errorf("area offset not applied")
+	}
+	inverted := gomonochromebitmap.ConvertImage(img, image.Rect(10, 2, 30, 8), gomonochromebitmap.ImageConversion{Threshold: 128, Invert: true})
+	if countOn(inverted) != 10*6-1 {
+		t.Errorf("invert failed")
+	}
+
+	//Area outside image gives empty bitmap
+	for method := gomonochromebitmap.CONVERT_THRESHOLD; method <= gomonochromebitmap.THRESHOLD_SAUVOLA; method++ {
+		bm := gomonochromebitmap.ConvertImage(img, image.Rect(40, 20, 60, 30), gomonochromebitmap.ImageConversion{Method: method, Invert: true})
+		if bm.W != 0 || bm.H != 0 {
+			t.Errorf("method %v gave %vx%v from disjoint area", method, bm.W, bm.H)
+		}
+	}
+	bm = gomonochromebitmap.NewMonoBitmapFromImage(img, image.Rect(-20, -20, -1, -1), 128, true)
+	if bm.W != 0 || bm.H != 0 {
+		t.Errorf("disjoint area gave %vx%v", bm.W, bm.H)
+	}
+}
+
+func TestDitherDog(t *testing.T) {
+	imgfile, err := os.Open("./testdata/dog.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer imgfile.Close()
+	pngimg, errDecode := png.Decode(imgfile)
+	if errDecode != nil {
+		t.Fatal(errDecode)
+	}
+	colTrue := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	colFalse := color.RGBA{R: 0, G: 0, B: 0, A: 255}
+	for name, method := range conversionMethods {
+		bm := gomonochromebitmap.ConvertImage(pngimg, pngimg.Bounds(), gomonochromebitmap.ImageConversion{Method: method, Luma: gomonochromebitmap.LUMA_REC709, Serpentine: true})
+		out, _ := os.Create(fmt.Sprintf("testDither_%s.png", name))
+		png.Encode(out, bm.GetImage(colTrue, colFalse))
+		out.Close()
+	}
+}
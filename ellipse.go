@@ -0,0 +1,172 @@
+/*
+Ellipses, arcs, pies and rounded rectangles
+
+Outlines are made with midpoint ellipse algorithm. Fills use half widths of
+same outline on each row, so fill always covers its outline.
+
+Negative radius draws nothing, like on CircleFill.
+
+Angles are radians. Zero points right and angles grow clockwise on screen
+(y axis points down), like on Rotation.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+	"math"
+)
+
+// ellipseQuadrant calls fn for outline points of first quadrant of ellipse centered at 0,0
+func ellipseQuadrant(rx int, ry int, fn func(x int, y int)) {
+	if rx < 0 || ry < 0 {
+		return
+	}
+	if ry == 0 {
+		for x := 0; x <= rx; x++ {
+			fn(x, 0)
+		}
+		return
+	}
+	rx2, ry2 := float64(rx*rx), float64(ry*ry)
+	x, y := 0, ry
+	px, py := 0.0, 2*rx2*float64(y)
+
+	//Region where slope is less than 1, x steps every time
+	d := ry2 - rx2*float64(ry) + rx2/4
+	for px < py {
+		fn(x, y)
+		x++
+		px += 2 * ry2
+		if d < 0 {
+			d += ry2 + px
+		} else {
+			y--
+			py -= 2 * rx2
+			d += ry2 + px - py
+		}
+	}
+
+	//Region where y steps every time
+	d = ry2*(float64(x)+0.5)*(float64(x)+0.5) + rx2*float64(y-1)*float64(y-1) - rx2*ry2
+	for 0 <= y {
+		fn(x, y)
+		y--
+		py -= 2 * rx2
+		if 0 < d {
+			d += rx2 - py
+		} else {
+			x++
+			px += 2 * ry2
+			d += rx2 - py + px
+		}
+	}
+}
+
+// ellipseHalfWidths returns widest outline x for each row 0...ry, nil on negative radius
+func ellipseHalfWidths(rx int, ry int) []int {
+	if rx < 0 || ry < 0 {
+		return nil
+	}
+	result := make([]int, ry+1)
+	ellipseQuadrant(rx, ry, func(x int, y int) {
+		result[y] = max(result[y], x)
+	})
+	return result
+}
+
+// Ellipse draws outline of ellipse with radius rx horizontally and ry vertically
+func (p *MonoBitmap) Ellipse(center image.Point, rx int, ry int, value bool) {
+	ellipseQuadrant(rx, ry, func(x int, y int) {
+		p.SetPix(center.X+x, center.Y+y, value)
+		p.SetPix(center.X-x, center.Y+y, value)
+		p.SetPix(center.X+x, center.Y-y, value)
+		p.SetPix(center.X-x, center.Y-y, value)
+	})
+}
+
+// EllipseFill fills ellipse and its outline
+func (p *MonoBitmap) EllipseFill(center image.Point, rx int, ry int, value bool) {
+	for y, half := range ellipseHalfWidths(rx, ry) {
+		p.Hline(center.X-half, center.X+half, center.Y+y, value)
+		p.Hline(center.X-half, center.X+half, center.Y-y, value)
+	}
+}
+
+// inAngle checks is direction dx,dy between start and end, going clockwise from start
+func inAngle(dx int, dy int, start float64, end float64) bool {
+	span := end - start
+	if 2*math.Pi <= span {
+		return true
+	}
+	span = math.Mod(span, 2*math.Pi)
+	if span < 0 {
+		span += 2 * math.Pi
+	}
+	a := math.Mod(math.Atan2(float64(dy), float64(dx))-start, 2*math.Pi)
+	if a < 0 {
+		a += 2 * math.Pi
+	}
+	return a <= span
+}
+
+// Arc draws part of ellipse outline from start angle clockwise to end angle. Use same rx and ry for circular arc
+func (p *MonoBitmap) Arc(center image.Point, rx int, ry int, start float64, end float64, value bool) {
+	ellipseQuadrant(rx, ry, func(x int, y int) {
+		for _, d := range []image.Point{{x, y}, {-x, y}, {x, -y}, {-x, -y}} {
+			if inAngle(d.X, d.Y, start, end) {
+				p.SetPix(center.X+d.X, center.Y+d.Y, value)
+			}
+		}
+	})
+}
+
+// Pie fills slice of ellipse from start angle clockwise to end angle
+func (p *MonoBitmap) Pie(center image.Point, rx int, ry int, start float64, end float64, value bool) {
+	if rx < 0 || ry < 0 {
+		return
+	}
+	for y, half := range ellipseHalfWidths(rx, ry) {
+		for x := -half; x <= half; x++ {
+			if inAngle(x, y, start, end) {
+				p.SetPix(center.X+x, center.Y+y, value)
+			}
+			if inAngle(x, -y, start, end) {
+				p.SetPix(center.X+x, center.Y-y, value)
+			}
+		}
+	}
+	p.SetPix(center.X, center.Y, value)
+}
+
+// roundCorners returns corner radius limited to area and centers of top left and bottom right corner arcs
+func roundCorners(area image.Rectangle, radius int) (int, image.Point, image.Point) {
+	r := max(0, min(radius, (area.Dx())/2, (area.Dy())/2))
+	return r, area.Min.Add(image.Pt(r, r)), area.Max.Sub(image.Pt(r, r))
+}
+
+// RoundRectangle draws outline of rectangle with rounded corners. Area includes Max like on Rectangle
+func (p *MonoBitmap) RoundRectangle(area image.Rectangle, radius int, value bool) {
+	r, c0, c1 := roundCorners(area, radius)
+	p.Hline(c0.X, c1.X, area.Min.Y, value)
+	p.Hline(c0.X, c1.X, area.Max.Y, value)
+	p.Vline(area.Min.X, c0.Y, c1.Y, value)
+	p.Vline(area.Max.X, c0.Y, c1.Y, value)
+	ellipseQuadrant(r, r, func(x int, y int) {
+		p.SetPix(c0.X-x, c0.Y-y, value)
+		p.SetPix(c1.X+x, c0.Y-y, value)
+		p.SetPix(c0.X-x, c1.Y+y, value)
+		p.SetPix(c1.X+x, c1.Y+y, value)
+	})
+}
+
+// RoundRectangleFill fills rectangle with rounded corners. Area includes Max like on Fill
+func (p *MonoBitmap) RoundRectangleFill(area image.Rectangle, radius int, value bool) {
+	r, c0, c1 := roundCorners(area, radius)
+	for y := c0.Y; y <= c1.Y; y++ {
+		p.Hline(area.Min.X, area.Max.X, y, value)
+	}
+	for y, half := range ellipseHalfWidths(r, r) {
+		p.Hline(c0.X-half, c1.X+half, c0.Y-y, value)
+		p.Hline(c0.X-half, c1.X+half, c1.Y+y, value)
+	}
+}
@@ -0,0 +1,212 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestEllipse(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(17, 9, false)
+	bm.Ellipse(image.Pt(8, 4), 8, 4, true)
+	expected := parseBitmap(
+		".....#######.....",
+		"..###.......###..",
+		".#.............#.",
+		"#...............#",
+		"#...............#",
+		"#...............#",
+		".#.............#.",
+		"..###.......###..",
+		".....#######.....",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("outline %v", bitmapString(bm))
+	}
+	bm.EllipseFill(image.Pt(8, 4), 8, 4, true)
+	expected = parseBitmap(
+		".....#######.....",
+		"..#############..",
+		".###############.",
+		"#################",
+		"#################",
+		"#################",
+		".###############.",
+		"..#############..",
+		".....#######.....",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("fill %v", bitmapString(bm))
+	}
+
+	tall := gomonochromebitmap.NewMonoBitmap(7, 13, false)
+	tall.Ellipse(image.Pt(3, 6), 3, 6, true)
+	expected = parseBitmap(
+		"..###..",
+		".#...#.",
+		".#...#.",
+		"#.....#",
+		"#.....#",
+		"#.....#",
+		"#.....#",
+		"#.....#",
+		"#.....#",
+		"#.....#",
+		".#...#.",
+		".#...#.",
+		"..###..",
+	)
+	if !equalBitmaps(expected, tall) {
+		t.Errorf("tall %v", bitmapString(tall))
+	}
+
+	flat := gomonochromebitmap.NewMonoBitmap(9, 3, false)
+	flat.Ellipse(image.Pt(4, 1), 4, 0, true)
+	if !equalBitmaps(parseBitmap(".........", "#########", "........."), flat) {
+		t.Errorf("flat %v", bitmapString(flat))
+	}
+
+	//Fill covers outline on all sizes
+	for rx := 0; rx < 20; rx += 3 {
+		for ry := 0; ry < 20; ry += 4 {
+			outline := gomonochromebitmap.NewMonoBitmap(41, 41, false)
+			outline.Ellipse(image.Pt(20, 20), rx, ry, true)
+			fill := gomonochromebitmap.NewMonoBitmap(41, 41, false)
+			fill.EllipseFill(image.Pt(20, 20), rx, ry, true)
+			outline.DrawBitmapOp(fill, fill.Bounds(), image.Point{}, gomonochromebitmap.ROP_ANDNOT)
+			if countOn(outline) != 0 {
+				t.Errorf("fill %vx%v does not cover outline", rx, ry)
+			}
+		}
+	}
+}
+
+func TestArcAndPie(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(13, 13, false)
+	bm.Arc(image.Pt(6, 6), 6, 6, math.Pi, 1.5*math.Pi, true)
+	expected := parseBitmap(
+		"....###......",
+		"...#.........",
+		"..#..........",
+		".#...........",
+		"#............",
+		"#............",
+		"#............",
+		".............",
+		".............",
+		".............",
+		".............",
+		".............",
+		".............",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("arc %v", bitmapString(bm))
+	}
+
+	bm = gomonochromebitmap.NewMonoBitmap(13, 13, false)
+	bm.Pie(image.Pt(6, 6), 6, 6, -math.Pi/2, math.Pi/4, true)
+	expected = parseBitmap(
+		"......###....",
+		"......####...",
+		"......#####..",
+		"......######.",
+		"......#######",
+		"......#######",
+		"......#######",
+		".......######",
+		"........#####",
+		".........###.",
+		"..........#..",
+		".............",
+		".............",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("pie %v", bitmapString(bm))
+	}
+
+	//Full turn is whole ellipse, also when angles wrap around
+	full := gomonochromebitmap.NewMonoBitmap(21, 15, false)
+	full.EllipseFill(image.Pt(10, 7), 10, 7, true)
+	pie := gomonochromebitmap.NewMonoBitmap(21, 15, false)
+	pie.Pie(image.Pt(10, 7), 10, 7, 1, 1+2*math.Pi, true)
+	if !equalBitmaps(full, pie) {
+		t.Errorf("full pie %v", bitmapString(pie))
+	}
+	pie = gomonochromebitmap.NewMonoBitmap(21, 15, false)
+	pie.Pie(image.Pt(10, 7), 10, 7, 1.5*math.Pi, 0.5*math.Pi, true)
+	pie.Pie(image.Pt(10, 7), 10, 7, -1.5*math.Pi, -0.5*math.Pi, true)
+	if !equalBitmaps(full, pie) {
+		t.Errorf("two halves %v", bitmapString(pie))
+	}
+}
+
+func TestRoundRectangle(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(14, 9, false)
+	bm.RoundRectangle(image.Rect(0, 0, 13, 8), 3, true)
+	expected := parseBitmap(
+		"..##########..",
+		".#..........#.",
+		"#............#",
+		"#............#",
+		"#............#",
+		"#............#",
+		"#............#",
+		".#..........#.",
+		"..##########..",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("outline %v", bitmapString(bm))
+	}
+	bm.RoundRectangleFill(image.Rect(0, 0, 13, 8), 3, true)
+	expected = parseBitmap(
+		"..##########..",
+		".############.",
+		"##############",
+		"##############",
+		"##############",
+		"##############",
+		"##############",
+		".############.",
+		"..##########..",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("fill %v", bitmapString(bm))
+	}
+
+	//Zero radius is normal rectangle
+	square := gomonochromebitmap.NewMonoBitmap(14, 9, false)
+	square.RoundRectangleFill(image.Rect(2, 1, 10, 6), 0, true)
+	plain := gomonochromebitmap.NewMonoBitmap(14, 9, false)
+	plain.Fill(image.Rect(2, 1, 10, 6), true)
+	if !equalBitmaps(plain, square) {
+		t.Errorf("zero radius %v", bitmapString(square))
+	}
+	square = gomonochromebitmap.NewMonoBitmap(14, 9, false)
+	square.RoundRectangle(image.Rect(2, 1, 10, 6), 0, true)
+	plain = gomonochromebitmap.NewMonoBitmap(14, 9, false)
+	plain.Rectangle(image.Rect(2, 1, 10, 6))
+	if !equalBitmaps(plain, square) {
+		t.Errorf("zero radius outline %v", bitmapString(square))
+	}
+}
+
+func TestEllipseNegativeRadius(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(20, 20, false)
+	c := image.Pt(10, 10)
+	for _, r := range []image.Point{{5, -3}, {-5, 3}, {-1, -1}, {-7, 0}} {
+		bm.Ellipse(c, r.X, r.Y, true)
+		bm.EllipseFill(c, r.X, r.Y, true)
+		bm.Arc(c, r.X, r.Y, 0, 1, true)
+		bm.Pie(c, r.X, r.Y, 0, 1, true)
+	}
+	bm.RoundRectangle(image.Rect(2, 2, 8, 8), -3, true)
+	bm.RoundRectangleFill(image.Rect(12, 12, 18, 18), -3, true)
+	expected := gomonochromebitmap.NewMonoBitmap(20, 20, false)
+	expected.Rectangle(image.Rect(2, 2, 8, 8))
+	expected.Fill(image.Rect(12, 12, 18, 18), true)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("negative radius drew %v", bitmapString(bm))
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Fake display panel for testing drivers without hardware
+
+FakePanel records all transactions and runs commands like page addressed
+controller. Data writes go to emulated GRAM, Bitmap returns its content.
+*/
+package gomonochromebitmap
+
+import (
+	"fmt"
+)
+
+// Commands having argument bytes
+var (
+	ssd1306CommandArgs = map[byte]int{0x20: 1, 0x21: 2, 0x22: 2, 0x81: 1, 0x8D: 1, 0xA8: 1, 0xAD: 1, 0xD3: 1, 0xD5: 1, 0xD9: 1, 0xDA: 1, 0xDB: 1}
+	st7565CommandArgs  = map[byte]int{0x81: 1, 0xF8: 1}
+)
+
+// FakePanel emulates SSD1306, SH1106 or ST7565. Implements DisplayBus, I2CTransactor and io.Writer (each Write is one I2C transaction)
+type FakePanel struct {
+	Layout       PageLayout
+	ST7565       bool     // ST7565 command set. 0x20-0x2F are then single byte power commands
+	Transactions [][]byte // Every transaction, first byte is control byte
+	GRAM         []byte   // Controller memory, Layout.Columns bytes per page
+	On           bool
+	Inverted     bool
+	Contrast     byte
+
+	w, h      int
+	page      int
+	column    int
+	mode      byte // SSD1306 addressing mode, 0 horizontal, 1 vertical, 2 page
+	colStart  int
+	colEnd    int
+	pageStart int
+	pageEnd   int
+	pending   []byte //Command waiting for argument bytes
+}
+
+// NewFakePanel creates w*h panel with cleared memory
+func NewFakePanel(layout PageLayout, w int, h int, st7565 bool) *FakePanel {
+	pages := (h + 7) / 8
+	return &FakePanel{
+		Layout:  layout,
+		ST7565:  st7565,
+		GRAM:    make([]byte, layout.Columns*pages),
+		w:       w,
+		h:       h,
+		mode:    2,
+		colEnd:  layout.Columns - 1,
+		pageEnd: pages - 1,
+	}
+}
+
+// Bitmap returns visible part of GRAM. Inverted and On flags are not applied
+func (p *FakePanel) Bitmap() MonoBitmap {
+	result, _ := p.Layout.ImportGRAM(p.GRAM, p.w, p.h)
+	return result
+}
+
+// Tx runs I2C write transaction. Nothing is read
+func (p *FakePanel) Tx(w []byte, r []byte) error {
+	return p.transaction(w)
+}
+
+func (p *FakePanel) Write(b []byte) (int, error) {
+	if err := p.transaction(b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (p *FakePanel) WriteCommand(cmd []byte) error {
+	return p.transaction(append([]byte{i2cControlCommand}, cmd...))
+}
+
+func (p *FakePanel) WriteData(data []byte) error {
+	return p.transaction(append([]byte{i2cControlData}, data...))
+}
+
+// transaction parses control bytes. With Co bit set only one byte follows control byte
+func (p *FakePanel) transaction(b []byte) error {
+	p.Transactions = append(p.Transactions, append([]byte{}, b...))
+	for 0 < len(b) {
+		control := b[0]
+		if control&0x3F != 0 {
+			return fmt.Errorf("invalid control byte %x", control)
+		}
+		payload := b[1:]
+		if control&0x80 != 0 && 1 < len(payload) {
+			payload = payload[:1]
+		}
+		for _, v := range payload {
+			if control&i2cControlData != 0 {
+				p.data(v)
+			} else {
+				p.command(v)
+			}
+		}
+		b = b[1+len(payload):]
+	}
+	return nil
+}
+
+// command collects command and its arguments, then runs it
+func (p *FakePanel) command(v byte) {
+	p.pending = append(p.pending, v)
+	args := ssd1306CommandArgs
+	if p.ST7565 {
+		args = st7565CommandArgs
+	}
+	if len(p.pending) <= args[p.pending[0]] {
+		return
+	}
+	cmd := p.pending
+	p.pending = nil
+
+	switch {
+	case cmd[0] == CMD_DISPLAY_OFF:
+		p.On = false
+	case cmd[0] == CMD_DISPLAY_ON:
+		p.On = true
+	case cmd[0] == CMD_NORMAL:
+		p.Inverted = false
+	case cmd[0] == CMD_INVERSE:
+		p.Inverted = true
+	case cmd[0] == CMD_CONTRAST:
+		p.Contrast = cmd[1]
+	case cmd[0]&0xF0 == CMD_PAGE_ADDRESS:
+		p.page = int(cmd[0] & 0x0F)
+	case cmd[0]&0xF0 == CMD_COLUMN_HIGH:
+		p.column = p.column&0x0F | int(cmd[0]&0x0F)<<4
+	case cmd[0]&0xF0 == CMD_COLUMN_LOW:
+		p.column = p.column&0xF0 | int(cmd[0]&0x0F)
+	case p.ST7565:
+		//Power control and other settings do not change memory
+	case cmd[0] == CMD_ADDRESSING:
+		p.mode = cmd[1] & 3
+	case cmd[0] == CMD_COLUMN_WINDOW:
+		p.colStart, p.colEnd = int(cmd[1]), int(cmd[2])
+		p.column = p.colStart
+	case cmd[0] == CMD_PAGE_WINDOW:
+		p.pageStart, p.pageEnd = int(cmd[1]), int(cmd[2])
+		p.page = p.pageStart
+	}
+}
+
+// data writes byte on GRAM and moves address pointer like controller
+func (p *FakePanel) data(v byte) {
+	if 0 <= p.page && p.page*p.Layout.Columns < len(p.GRAM) && p.column < p.Layout.Columns {
+		p.GRAM[p.page*p.Layout.Columns+p.column] = v
+	}
+	switch p.mode {
+	case 0: //Horizontal
+		p.column++
+		if p.colEnd < p.column {
+			p.column = p.colStart
+			p.page++
+			if p.pageEnd < p.page {
+				p.page = p.pageStart
+			}
+		}
+	case 1: //Vertical
+		p.page++
+		if p.pageEnd < p.page {
+			p.page = p.pageStart
+			p.column++
+			if p.colEnd < p.column {
+				p.column = p.colStart
+			}
+		}
+	default: //Page addressing stays on page
+		p.column++
+		if p.Layout.Columns <= p.column {
+			p.column = 0
+		}
+	}
+}
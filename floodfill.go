@@ -0,0 +1,117 @@
+/*
+Flood fill
+
+Scanline fill with explicit stack, so large areas do not need deep recursion.
+Area is first collected as horizontal spans into mask, then painted. That way
+pattern fill works even when pattern has pixels of same value as area.
+
+Pattern is anchored on its Origin on root bitmap coordinates, so adjacent fills
+with same pattern continue seamlessly, also across SubBitmap views.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+)
+
+// fillSpan is filled pixels x0...x1 (inclusive) on row y
+type fillSpan struct {
+	y, x0, x1 int
+}
+
+// fillArea finds area connected to seed where pixels have target value
+func (p *MonoBitmap) fillArea(seed image.Point, target bool, conn Connectivity) []fillSpan {
+	if !seed.In(p.LocalBounds()) || p.GetPixNoCheck(seed.X, seed.Y) != target {
+		return nil
+	}
+	reach := 0
+	if conn == CONNECT_8 {
+		reach = 1
+	}
+	visited := NewMonoBitmap(p.W, p.H, false)
+	fillable := func(x int, y int) bool {
+		return p.GetPixNoCheck(x, y) == target && !visited.GetPixNoCheck(x, y)
+	}
+
+	var result []fillSpan
+	stack := []image.Point{seed}
+	for 0 < len(stack) {
+		q := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !fillable(q.X, q.Y) {
+			continue
+		}
+		x0, x1 := q.X, q.X
+		for 0 < x0 && fillable(x0-1, q.Y) {
+			x0--
+		}
+		for x1 < p.W-1 && fillable(x1+1, q.Y) {
+			x1++
+		}
+		visited.Hline(x0, x1, q.Y, true)
+		result = append(result, fillSpan{y: q.Y, x0: x0, x1: x1})
+
+		//Push first pixel of each fillable segment on rows above and below
+		for _, y := range []int{q.Y - 1, q.Y + 1} {
+			if y < 0 || p.H <= y {
+				continue
+			}
+			inSegment := false
+			for x := max(x0-reach, 0); x <= min(x1+reach, p.W-1); x++ {
+				if !fillable(x, y) {
+					inSegment = false
+					continue
+				}
+				if !inSegment {
+					stack = append(stack, image.Pt(x, y))
+					inSegment = true
+				}
+			}
+		}
+	}
+	return result
+}
+
+// paintSpans fills spans with value or with pattern if it is not nil. Returns bounding box of changed pixels
+func (p *MonoBitmap) paintSpans(spans []fillSpan, value bool, pattern *Pattern) image.Rectangle {
+	var changed image.Rectangle
+	for _, s := range spans {
+		if pattern == nil {
+			p.Hline(s.x0, s.x1, s.y, value)
+			changed = changed.Union(image.Rect(s.x0, s.y, s.x1+1, s.y+1))
+			continue
+		}
+		for x := s.x0; x <= s.x1; x++ {
+			v := pattern.At(x+p.Origin.X, s.y+p.Origin.Y)
+			if p.GetPixNoCheck(x, s.y) != v {
+				p.SetPixNoCheck(x, s.y, v)
+				changed = changed.Union(image.Rect(x, s.y, x+1, s.y+1))
+			}
+		}
+	}
+	return changed
+}
+
+// FloodFill sets area connected to seed having same value as seed. Returns bounding box of changed pixels
+func (p *MonoBitmap) FloodFill(seed image.Point, value bool, conn Connectivity) image.Rectangle {
+	if !seed.In(p.LocalBounds()) || p.GetPixNoCheck(seed.X, seed.Y) == value {
+		return image.Rectangle{}
+	}
+	return p.paintSpans(p.fillArea(seed, !value, conn), value, nil)
+}
+
+// FloodFillPattern fills area connected to seed having same value as seed with tiled pattern. Returns bounding box of changed pixels
+func (p *MonoBitmap) FloodFillPattern(seed image.Point, pattern Pattern, conn Connectivity) image.Rectangle {
+	if !seed.In(p.LocalBounds()) || pattern.Tile.W == 0 || pattern.Tile.H == 0 {
+		return image.Rectangle{}
+	}
+	return p.paintSpans(p.fillArea(seed, p.GetPixNoCheck(seed.X, seed.Y), conn), false, &pattern)
+}
+
+// BoundaryFill fills area around seed up to pixels having boundary value. Nil pattern fills with boundary value. Returns bounding box of changed pixels
+func (p *MonoBitmap) BoundaryFill(seed image.Point, boundary bool, pattern *Pattern, conn Connectivity) image.Rectangle {
+	if pattern != nil && (pattern.Tile.W == 0 || pattern.Tile.H == 0) {
+		return image.Rectangle{}
+	}
+	return p.paintSpans(p.fillArea(seed, !boundary, conn), boundary, pattern)
+}
@@ -0,0 +1,119 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestFloodFillReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(34))
+	for i := 0; i < 30; i++ {
+		bm := randomBitmap(rnd, 1+rnd.Intn(80), 1+rnd.Intn(40))
+		seed := image.Pt(rnd.Intn(bm.W), rnd.Intn(bm.H))
+		bm.SetPix(seed.X, seed.Y, true)
+		for _, conn := range []gomonochromebitmap.Connectivity{gomonochromebitmap.CONNECT_4, gomonochromebitmap.CONNECT_8} {
+			labels, _ := refLabel(bm, conn == gomonochromebitmap.CONNECT_8)
+			filled := bm.Clone()
+			changed := filled.FloodFill(seed, false, conn)
+
+			var expected image.Rectangle
+			for y := 0; y < bm.H; y++ {
+				for x := 0; x < bm.W; x++ {
+					inArea := labels[x+bm.W*y] == labels[seed.X+bm.W*seed.Y]
+					if inArea {
+						expected = expected.Union(image.Rect(x, y, x+1, y+1))
+					}
+					if filled.GetPixNoCheck(x, y) != (bm.GetPixNoCheck(x, y) && !inArea) {
+						t.Fatalf("%v-connectivity fill differs at %v,%v", conn, x, y)
+					}
+				}
+			}
+			if changed != expected {
+				t.Errorf("changed %v expected %v", changed, expected)
+			}
+		}
+	}
+}
+
+func TestFloodFillConnectivity(t *testing.T) {
+	bm := parseBitmap(
+		"...#....",
+		"..#.....",
+		".#......",
+		"#.......",
+	)
+	four := bm.Clone()
+	four.FloodFill(image.Pt(0, 0), true, gomonochromebitmap.CONNECT_4)
+	if countOn(four) != 10 {
+		t.Errorf("4-connected fill leaked %v", bitmapString(four))
+	}
+	eight := bm.Clone()
+	eight.FloodFill(image.Pt(0, 0), true, gomonochromebitmap.CONNECT_8)
+	if countOn(eight) != 32 {
+		t.Errorf("8-connected fill did not pass diagonal %v", bitmapString(eight))
+	}
+	if changed := eight.FloodFill(image.Pt(3, 3), true, gomonochromebitmap.CONNECT_4); !changed.Empty() {
+		t.Errorf("fill with same value changed %v", changed)
+	}
+	if changed := eight.FloodFill(image.Pt(-1, 3), false, gomonochromebitmap.CONNECT_4); !changed.Empty() {
+		t.Errorf("seed outside changed %v", changed)
+	}
+}
+
+func TestFloodFillLarge(t *testing.T) {
+	//Comb of walls forces long winding fill path
+	bm := gomonochromebitmap.NewMonoBitmap(1000, 1000, false)
+	for x := 1; x < 1000; x += 2 {
+		if x%4 == 1 {
+			bm.Vline(x, 0, 998, true)
+		} else {
+			bm.Vline(x, 1, 999, true)
+		}
+	}
+	changed := bm.FloodFill(image.Pt(0, 0), true, gomonochromebitmap.CONNECT_4)
+	if countOn(bm) != 1000*1000 || changed != image.Rect(0, 0, 1000, 1000) {
+		t.Errorf("%v pixels on, changed %v", countOn(bm), changed)
+	}
+}
+
+func TestPatternFill(t *testing.T) {
+	checker := gomonochromebitmap.NewPattern(parseBitmap("#.", ".#"))
+	bm := gomonochromebitmap.NewMonoBitmap(10, 8, false)
+	bm.Rectangle(image.Rect(1, 1, 8, 6))
+	outline := bm.Clone()
+
+	changed := bm.BoundaryFill(image.Pt(4, 3), true, &checker, gomonochromebitmap.CONNECT_4)
+	for y := 0; y < bm.H; y++ {
+		for x := 0; x < bm.W; x++ {
+			expected := outline.GetPixNoCheck(x, y)
+			if 1 < x && x < 8 && 1 < y && y < 6 {
+				expected = (x+y)%2 == 0
+			}
+			if bm.GetPixNoCheck(x, y) != expected {
+				t.Fatalf("pattern differs at %v,%v %v", x, y, bitmapString(bm))
+			}
+		}
+	}
+	if changed != image.Rect(2, 2, 8, 6) {
+		t.Errorf("changed %v", changed)
+	}
+
+	//Pattern fill covers only area of seed value, inside of outline is not touched
+	filled := bm.Clone()
+	filled.FloodFillPattern(image.Pt(0, 0), checker, gomonochromebitmap.CONNECT_4)
+	if !filled.GetPix(0, 0) || filled.GetPix(1, 0) || !filled.GetPix(9, 7) || !filled.GetPix(2, 1) || filled.GetPix(3, 2) {
+		t.Errorf("pattern fill outside %v", bitmapString(filled))
+	}
+
+	//Solid boundary fill
+	solid := outline.Clone()
+	solid.BoundaryFill(image.Pt(4, 3), true, nil, gomonochromebitmap.CONNECT_4)
+	expected := gomonochromebitmap.NewMonoBitmap(10, 8, false)
+	expected.Fill(image.Rect(1, 1, 8, 6), true)
+	if !equalBitmaps(expected, solid) {
+		t.Errorf("boundary fill %v", bitmapString(solid))
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Frame differences for animations and remote display updates
+
+DiffRects finds changed areas between two frames. Rectangles are merged when
+sending one larger rectangle is cheaper than sending two, using cost
+overhead + packed bytes of rectangle.
+
+Delta packet
+- magic "MDLT"
+- version byte, 1
+- width, height and number of patches as unsigned varints
+- each patch: x, y, w, h and data length as unsigned varints, then data tagged by EncodeSmallest
+*/
+package gomonochromebitmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"math/bits"
+)
+
+const (
+	deltaMagic   = "MDLT"
+	deltaVersion = 1
+
+	DEFAULT_RECT_OVERHEAD = 8 // Bytes spent on rectangle header, used when overhead is 0
+)
+
+// rectCost is number of bytes needed for sending rectangle
+func rectCost(r image.Rectangle, overhead int) int {
+	return overhead + r.Dy()*((r.Dx()+7)/8)
+}
+
+// rowDiff returns changed pixel ranges [x0,x1) on row y. Ranges closer than gap pixels are joined
+func rowDiff(a *MonoBitmap, b *MonoBitmap, y int, gap int) [][2]int {
+	result := [][2]int{}
+	aBit, bBit := a.rowBit(y), b.rowBit(y)
+	for x := 0; x < a.W; x += 32 {
+		d := fetchBits(a.Pix, aBit+x) ^ fetchBits(b.Pix, bBit+x)
+		if n := a.W - x; n < 32 {
+			d &= uint32(0xFFFFFFFF) >> uint32(32-n)
+		}
+		if d == 0 {
+			continue
+		}
+		x0 := x + bits.TrailingZeros32(d)
+		x1 := x + 32 - bits.LeadingZeros32(d)
+		if last := len(result) - 1; 0 <= last && x0-result[last][1] <= gap {
+			result[last][1] = x1
+		} else {
+			result = append(result, [2]int{x0, x1})
+		}
+	}
+	return result
+}
+
+// DirtyRows returns rows that differ. Bitmaps must have same size
+func DirtyRows(a *MonoBitmap, b *MonoBitmap) []int {
+	result := []int{}
+	if a.W != b.W || a.H != b.H {
+		for y := 0; y < b.H; y++ {
+			result = append(result, y)
+		}
+		return result
+	}
+	for y := 0; y < a.H; y++ {
+		if 0 < len(rowDiff(a, b, y, a.W)) {
+			result = append(result, y)
+		}
+	}
+	return result
+}
+
+// DiffRects returns rectangles covering all changed pixels between a and b. Overhead is cost of rectangle in bytes, 0 uses DEFAULT_RECT_OVERHEAD. Different sized bitmaps give whole b
+func DiffRects(a *MonoBitmap, b *MonoBitmap, overhead int) []image.Rectangle {
+	if overhead <= 0 {
+		overhead = DEFAULT_RECT_OVERHEAD
+	}
+	if a.W != b.W || a.H != b.H {
+		if b.LocalBounds().Empty() {
+			return []image.Rectangle{}
+		}
+		return []image.Rectangle{b.LocalBounds()}
+	}
+
+	//Grow rectangles downwards row by row. Rectangle that is not continued on next row is closed
+	closed := []image.Rectangle{}
+	open := []image.Rectangle{}
+	for y := 0; y < a.H; y++ {
+		next := []image.Rectangle{}
+		for _, seg := range rowDiff(a, b, y, overhead*8) {
+			r := image.Rect(seg[0], y, seg[1], y+1)
+			merged := false
+			for i, o := range open {
+				u := o.Union(r)
+				if rectCost(u, overhead) <= rectCost(o, overhead)+rectCost(r, overhead) {
+					open[i] = u
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				next = append(next, r)
+			}
+		}
+		for _, o := range open {
+			if o.Max.Y == y+1 {
+				next = append(next, o)
+			} else {
+				closed = append(closed, o)
+			}
+		}
+		open = next
+	}
+	return mergeRects(append(closed, open...), overhead)
+}
+
+// mergeRects joins rectangles while union is not more expensive than parts
+func mergeRects(rects []image.Rectangle, overhead int) []image.Rectangle {
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(rects) && !merged; i++ {
+			for j := i + 1; j < len(rects); j++ {
+				u := rects[i].Union(rects[j])
+				if rectCost(u, overhead) <= rectCost(rects[i], overhead)+rectCost(rects[j], overhead) {
+					rects[i] = u
+					rects = append(rects[:j], rects[j+1:]...)
+					merged = true
+					break
+				}
+			}
+		}
+	}
+	return rects
+}
+
+// EncodeDelta creates packet that turns base into frame with ApplyDelta
+func EncodeDelta(base *MonoBitmap, frame *MonoBitmap, overhead int) []byte {
+	rects := DiffRects(base, frame, overhead)
+	var buf bytes.Buffer
+	buf.WriteString(deltaMagic)
+	buf.WriteByte(deltaVersion)
+	writeUvarints(&buf, frame.W, frame.H, len(rects))
+	for _, r := range rects {
+		patch := frame.SubBitmap(r)
+		data := EncodeSmallest(&patch, nil)
+		writeUvarints(&buf, r.Min.X, r.Min.Y, r.Dx(), r.Dy(), len(data))
+		buf.Write(data)
+	}
+	return buf.Bytes()
+}
+
+// ApplyDelta draws patches of delta packet on base. Returns updated rectangles. Base must have same size as frame on encoding
+func ApplyDelta(base *MonoBitmap, packet []byte) ([]image.Rectangle, error) {
+	in := bytes.NewReader(packet)
+	header := make([]byte, len(deltaMagic)+1)
+	if n, _ := in.Read(header); n < len(header) || string(header[:len(deltaMagic)]) != deltaMagic {
+		return nil, fmt.Errorf("not delta packet")
+	}
+	if header[len(deltaMagic)] != deltaVersion {
+		return nil, fmt.Errorf("unsupported delta version %v", header[len(deltaMagic)])
+	}
+	size, err := readUvarints(in, 3)
+	if err != nil {
+		return nil, fmt.Errorf("delta header: %w", err)
+	}
+	if size[0] != base.W || size[1] != base.H {
+		return nil, fmt.Errorf("delta is for %vx%v bitmap, base is %vx%v", size[0], size[1], base.W, base.H)
+	}
+
+	//Decode all patches before drawing, so invalid packet does not leave base half updated
+	rects := make([]image.Rectangle, 0, min(size[2], len(packet)))
+	patches := make([]MonoBitmap, 0, cap(rects))
+	for i := 0; i < size[2]; i++ {
+		v, errPatch := readUvarints(in, 5)
+		if errPatch != nil {
+			return nil, fmt.Errorf("delta patch %v: %w", i, errPatch)
+		}
+		r := image.Rect(v[0], v[1], v[0]+v[2], v[1]+v[3])
+		if r.Dx() != v[2] || r.Dy() != v[3] || !r.In(base.LocalBounds()) {
+			return nil, fmt.Errorf("delta patch %v %v outside bitmap", i, r)
+		}
+		if in.Len() < v[4] {
+			return nil, fmt.Errorf("delta patch %v data truncated", i)
+		}
+		data := make([]byte, v[4])
+		in.Read(data)
+		patch, errDecode := DecodeTagged(data, r.Dx(), r.Dy(), nil)
+		if errDecode != nil {
+			return nil, fmt.Errorf("delta patch %v: %w", i, errDecode)
+		}
+		rects = append(rects, r)
+		patches = append(patches, patch)
+	}
+	for i, patch := range patches {
+		base.DrawBitmapOp(patch, patch.LocalBounds(), rects[i].Min, ROP_COPY)
+	}
+	return rects, nil
+}
+
+func writeUvarints(buf *bytes.Buffer, values ...int) {
+	for _, v := range values {
+		buf.Write(binary.AppendUvarint(nil, uint64(v)))
+	}
+}
+
+// readUvarints reads n values, each must fit in int32
+func readUvarints(in *bytes.Reader, n int) ([]int, error) {
+	result := make([]int, n)
+	for i := range result {
+		v, err := binary.ReadUvarint(in)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if 1<<31 <= v {
+			return nil, fmt.Errorf("too large value %v", v)
+		}
+		result[i] = int(v)
+	}
+	return result, nil
+}
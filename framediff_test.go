@@ -0,0 +1,106 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestDiffRects(t *testing.T) {
+	a := gomonochromebitmap.NewMonoBitmap(128, 64, false)
+	b := a.Clone()
+	if rects := gomonochromebitmap.DiffRects(&a, &b, 0); len(rects) != 0 {
+		t.Errorf("same frames gave %v", rects)
+	}
+
+	//Close changes are merged, far away are not
+	b.SetPix(10, 10, true)
+	b.SetPix(12, 11, true)
+	b.SetPix(100, 50, true)
+	rects := gomonochromebitmap.DiffRects(&a, &b, 0)
+	if len(rects) != 2 {
+		t.Fatalf("got %v", rects)
+	}
+	for _, r := range rects {
+		if r != image.Rect(10, 10, 13, 12) && r != image.Rect(100, 50, 101, 51) {
+			t.Errorf("unexpected rectangle %v", r)
+		}
+	}
+	//Huge overhead puts all in one
+	if rects := gomonochromebitmap.DiffRects(&a, &b, 10000); len(rects) != 1 || rects[0] != image.Rect(10, 10, 101, 51) {
+		t.Errorf("got %v", rects)
+	}
+
+	rows := gomonochromebitmap.DirtyRows(&a, &b)
+	if len(rows) != 3 || rows[0] != 10 || rows[1] != 11 || rows[2] != 50 {
+		t.Errorf("dirty rows %v", rows)
+	}
+}
+
+func TestDiffRectsCoverChanges(t *testing.T) {
+	rnd := rand.New(rand.NewSource(21))
+	for round := 0; round < 50; round++ {
+		a := randomBitmap(rnd, 1+rnd.Intn(200), 1+rnd.Intn(50))
+		b := a.Clone()
+		for n := rnd.Intn(20); 0 < n; n-- {
+			x, y := rnd.Intn(a.W), rnd.Intn(a.H)
+			b.Fill(image.Rect(x, y, x+rnd.Intn(20), y+rnd.Intn(5)), rnd.Intn(2) == 0)
+		}
+		//Compare views too, bit alignment differs from normal bitmap
+		big := gomonochromebitmap.NewMonoBitmap(a.W+40, a.H+3, false)
+		view := big.SubBitmap(image.Rect(13, 3, 13+a.W, 3+a.H))
+		view.DrawBitmap(a, a.Bounds(), image.Point{}, true, true, false)
+
+		rects := gomonochromebitmap.DiffRects(&view, &b, 1+rnd.Intn(10))
+		for y := 0; y < a.H; y++ {
+			for x := 0; x < a.W; x++ {
+				inside := false
+				for _, r := range rects {
+					inside = inside || image.Pt(x, y).In(r)
+				}
+				if a.GetPix(x, y) != b.GetPix(x, y) && !inside {
+					t.Fatalf("round %v: changed pixel %v,%v not covered by %v", round, x, y, rects)
+				}
+			}
+		}
+	}
+}
+
+func TestDelta(t *testing.T) {
+	rnd := rand.New(rand.NewSource(22))
+	base := randomBitmap(rnd, 128, 64)
+	frame := base.Clone()
+	frame.Fill(image.Rect(5, 5, 40, 20), false)
+	frame.Print("12:45", gomonochromebitmap.GetFont_5x7(), 7, 1, image.Rect(6, 6, 40, 20), true, false, false, false)
+	frame.SetPix(120, 60, !frame.GetPix(120, 60))
+
+	packet := gomonochromebitmap.EncodeDelta(&base, &frame, 0)
+	if len(packet) > 128*64/8/4 {
+		t.Errorf("too big packet %v bytes", len(packet))
+	}
+	target := base.Clone()
+	rects, err := gomonochromebitmap.ApplyDelta(&target, packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rects) != 2 || !equalBitmaps(target, frame) {
+		t.Errorf("apply failed, %v", rects)
+	}
+
+	//Errors
+	small := gomonochromebitmap.NewMonoBitmap(10, 10, false)
+	if _, err := gomonochromebitmap.ApplyDelta(&small, packet); err == nil {
+		t.Errorf("size mismatch not detected")
+	}
+	for n := 0; n < len(packet); n++ {
+		untouched := base.Clone()
+		if _, err := gomonochromebitmap.ApplyDelta(&untouched, packet[:n]); err == nil {
+			t.Fatalf("truncated packet %v bytes accepted", n)
+		}
+		if !equalBitmaps(untouched, base) {
+			t.Fatalf("invalid packet modified base")
+		}
+	}
+}
@@ -5,74 +5,96 @@ import (
 	"fmt"
 	"image"
 	"image/color"
-	"math"
 )
 
+// MonoBitmap stores pixels row by row. Each row starts from word boundary, pixel x of row is bit x%32 of word x/32
+// SubBitmap views share Pix with parent and their rows start from bit OffsetX
 type MonoBitmap struct {
-	Pix []uint32 //using byte vs uint16 vs uint32 vs uint64...  32bit shoud suit well for raspi1/2
-	W   int
-	H   int
+	Pix     []uint32 //using byte vs uint16 vs uint32 vs uint64...  32bit shoud suit well for raspi1/2
+	W       int
+	H       int
+	Stride  int         //Number of words on each row
+	OffsetX int         //Bit offset of pixel x=0 on each row. Zero if bitmap is not view
+	Origin  image.Point //Position of view on root bitmap. Zero if bitmap is not view
+
+	dirty *dirtyTracker //Changed area, nil when tracking is off
+	view  bool          //Pix belongs to parent bitmap
+}
+
+// wordsPerRow is default stride for width w
+func wordsPerRow(w int) int {
+	return (w + 31) / 32
 }
 
 // NewMonoBitmap initializes empty bitmap fill is default value
 func NewMonoBitmap(w int, h int, fill bool) MonoBitmap {
-	result := MonoBitmap{W: w, H: h, Pix: make([]uint32, w*h/32+1)}
+	stride := wordsPerRow(w)
+	result := MonoBitmap{W: w, H: h, Stride: stride, Pix: make([]uint32, stride*h)}
 	if fill {
-		for index := range result.Pix {
-			result.Pix[index] = 0xFFFFFFFF
-		}
+		result.Fill(result.LocalBounds(), true)
 	}
 	return result
 }
 
-// NewMonoBitmapFromImage initializes bitmap from image. Color conversion: if any Red,Green or Blue value is over threshold then pixel is true
-func NewMonoBitmapFromImage(img image.Image, area image.Rectangle, threshold byte, invert bool) MonoBitmap {
-	b := img.Bounds()
-	w := b.Max.X
-	h := b.Max.Y
+// NewMonoBitmapFromFlatPix converts bitmap data from old flat layout (pixel index x+W*y, no row alignment) to row aligned bitmap
+func NewMonoBitmapFromFlatPix(pix []uint32, w int, h int) MonoBitmap {
 	result := NewMonoBitmap(w, h, false)
-	for x := 0; x <= w; x++ {
-		for y := 0; y < h; y++ {
-			vr, vg, vb, _ := img.At(x, y).RGBA()
-			v := byte((max(int(vr), max(int(vg), int(vb)))) >> 8)
-			if v > threshold {
-				result.SetPix(x, y, !invert)
-			} else {
-				result.SetPix(x, y, invert)
-			}
-		}
+	for y := 0; y < h; y++ {
+		blitBits(result.Pix, result.rowBit(y), pix, w*y, w, ROP_COPY)
 	}
 	return result
 }
 
-// Bounds returns W,H in Rect struct
+// Row returns words of row y. Slice is shared with bitmap. Pixel x is bit x+OffsetX, other bits are padding or belong outside of view
+func (p *MonoBitmap) Row(y int) []uint32 {
+	return p.Pix[y*p.Stride : y*p.Stride+wordsPerRow(p.OffsetX+p.W)]
+}
+
+// NewMonoBitmapFromImage initializes bitmap from area of image. Color conversion: if any Red,Green or Blue value is over threshold then pixel is true. ConvertImage supports dithering and automatic thresholds
+func NewMonoBitmapFromImage(img image.Image, area image.Rectangle, threshold byte, invert bool) MonoBitmap {
+	return ConvertImage(img, area, ImageConversion{Method: CONVERT_THRESHOLD, Luma: LUMA_MAX_CHANNEL, Threshold: threshold, Invert: invert})
+}
+
+// Bounds returns area of bitmap on root bitmap coordinates, like SubImage of image.RGBA. Same coordinates are used by At and Set (image.Image interface)
 func (p *MonoBitmap) Bounds() image.Rectangle {
+	return image.Rect(0, 0, p.W, p.H).Add(p.Origin)
+}
+
+// LocalBounds returns W,H in Rect struct. Drawing functions use these coordinates also on SubBitmap views
+func (p *MonoBitmap) LocalBounds() image.Rectangle {
 	return image.Rect(0, 0, p.W, p.H)
 }
 
-// RLEdecode decodes run length compressed bitmap data
+// RLEdecode decodes version 1 run length compressed bitmap data. Data is not modified
 func (p *MonoBitmap) RLEdecode(activeFirst bool, data []byte) error {
-	//TODO line drawing... LESS naive solution
+	if p.W*p.H == 0 {
+		return nil
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("no RLE data")
+	}
 	activeNow := activeFirst
+	pos := 0
+	left := int(data[0]) //pixels left on current run
 
 	for y := 0; y < p.H; y++ {
 		for x := 0; x < p.W; x++ {
-			for data[0] == 0 { //remove zeros
-				data = data[1:]
-				if len(data) == 0 {
+			for left == 0 { //skip zeros
+				pos++
+				if len(data) <= pos {
 					return fmt.Errorf("runned out of RLE data")
 				}
-				//fmt.Printf("data len =%v\n", len(data))
+				left = int(data[pos])
 				activeNow = !activeNow
 			}
-			data[0]--
-			p.SetPix(x, y, activeNow)
+			left--
+			p.SetPixNoCheck(x, y, activeNow)
 		}
 	}
 	return nil
 }
 
-// RLEencodes bitmap in runlength compressed format
+// RLEencodes bitmap in version 1 runlength compressed format. Prefer RLEencodeV2
 func (p *MonoBitmap) RLEencode(activeFirst bool) []byte {
 	counter := byte(0)
 	activeNow := activeFirst
@@ -108,25 +130,23 @@ func (p *MonoBitmap) GetImage(trueColor color.Color, falseColor color.Color) *im
 	trueR, trueG, trueB, trueA := trueColor.RGBA()
 	falseR, falseG, falseB, falseA := falseColor.RGBA()
 
-	offset := 0
-	//fmt.Printf("STRIDE %v\n", result.Stride)
-	n := p.W * p.H
-
-	for i := 0; i < n; i++ {
-		index := i / 32
-		pv := ((p.Pix[index] & uint32(1<<uint32(i%32))) > 0)
-		if pv {
-			result.Pix[offset+0] = uint8(trueR >> 8)
-			result.Pix[offset+1] = uint8(trueG >> 8)
-			result.Pix[offset+2] = uint8(trueB >> 8)
-			result.Pix[offset+3] = uint8(trueA >> 8)
-		} else {
-			result.Pix[offset+0] = uint8(falseR >> 8)
-			result.Pix[offset+1] = uint8(falseG >> 8)
-			result.Pix[offset+2] = uint8(falseB >> 8)
-			result.Pix[offset+3] = uint8(falseA >> 8)
+	for y := 0; y < p.H; y++ {
+		offset := y * result.Stride
+		row := p.Row(y)
+		for x := p.OffsetX; x < p.OffsetX+p.W; x++ {
+			if (row[x>>5] & (1 << uint32(x&31))) > 0 {
+				result.Pix[offset+0] = uint8(trueR >> 8)
+				result.Pix[offset+1] = uint8(trueG >> 8)
+				result.Pix[offset+2] = uint8(trueB >> 8)
+				result.Pix[offset+3] = uint8(trueA >> 8)
+			} else {
+				result.Pix[offset+0] = uint8(falseR >> 8)
+				result.Pix[offset+1] = uint8(falseG >> 8)
+				result.Pix[offset+2] = uint8(falseB >> 8)
+				result.Pix[offset+3] = uint8(falseA >> 8)
+			}
+			offset += 4
 		}
-		offset += 4
 	}
 	return result
 }
@@ -140,7 +160,7 @@ func CreatePlanarColorImage(planes []MonoBitmap, palette []color.Color) (*image.
 		return nil, fmt.Errorf("no input images")
 	}
 
-	result := image.NewRGBA(planes[0].Bounds())
+	result := image.NewRGBA(planes[0].LocalBounds())
 	//Check dimensions
 	for _, plane := range planes {
 		if plane.W != planes[0].W || plane.H != planes[0].H {
@@ -174,7 +194,7 @@ func (p *MonoBitmap) GetFgBgImage(fgPic image.Image, bgPic image.Image) (image.I
 		return nil, fmt.Errorf("bg %vX%v and fg %vX%v must be equal or larger than mono bitmap %v,%v", bb.Dx(), bb.Dy(), fb.Dx(), fb.Dy(), p.W, p.H)
 	}
 
-	result := image.NewRGBA(p.Bounds())
+	result := image.NewRGBA(p.LocalBounds())
 
 	//TODO optimize byte -> 8 pixels
 	for y := 0; y < p.H; y++ {
@@ -225,42 +245,13 @@ func (p *MonoBitmap) GetDisplayImage(trueColorUpper color.Color, trueColorDowner
 // pxStep=1 is 1:1
 // pxStep=2 is 2:1 (50% scale)
 // pxStep=3 is 3:1 (25% scale)
+// pxStep=-2 is 1:2 (200% scale)
 // pxStep is limited to point where whole bitmap is visible
-// Returns: image, actual cornerpoint and zoom used. Useful if UI includes
-func (p *MonoBitmap) GetView(w int, h int, p0 image.Point, pxStep int, edges bool) MonoBitmap {
-	result := NewMonoBitmap(w, h, false)
-	maxStep := math.Max(float64(p.W)/float64(w), float64(p.H)/float64(h)) //In decimal
-	corner := image.Point{X: max(p0.X, 0), Y: max(p0.Y, 0)}               //Limit point inside
-
-	var step float64
-
-	step = math.Min(float64(pxStep), math.Ceil(maxStep)) //Limits zooming out too much
-	if pxStep == 0 {                                     //Autoscale
-		step = maxStep
-		corner = image.Point{X: 0, Y: 0}
-		if maxStep <= 0.5 { //Scale bigger
-			//TODO: this is only reason why decimal step is now needed. Todo later integer step
-		} else {
-			step = math.Ceil(step)
-		}
-	}
-
-	//Limit corner
-	corner.X = min(corner.X, int(float64(p.W)-step*float64(w)))
-	corner.Y = min(corner.Y, int(float64(p.H)-step*float64(h)))
-
-	for x := 0; x < w; x++ {
-		for y := 0; y < h; y++ {
-			a := int(float64(x)*step) + corner.X
-			b := int(float64(y)*step) + corner.Y
-			if (a < 0) || (b < 0) || (p.W <= a) || (p.H <= b) {
-				result.SetPix(x, y, edges)
-			} else {
-				result.SetPix(x, y, p.GetPix(a, b))
-			}
-		}
-	}
-	return result
+// Returns: image, actual cornerpoint and zoom used (same way as pxStep). Useful if UI includes
+// GetViewWith supports coverage sampling on zooming out and upscalers on zooming in
+func (p *MonoBitmap) GetView(w int, h int, p0 image.Point, pxStep int, edges bool) (MonoBitmap, image.Point, int) {
+	view := p.GetViewWith(w, h, p0, pxStep, ViewOptions{Edges: edges})
+	return view.Bitmap, view.Corner, view.Step
 }
 
 // Fills rectangle area from map. Used for clearing image
@@ -279,21 +270,16 @@ func (p *MonoBitmap) Rectangle(area image.Rectangle) {
 	p.Vline(area.Max.X, area.Min.Y, area.Max.Y, true)
 }
 
-// Inverts pixel values
+// Inverts pixel values. Area includes Max like on Fill
 func (p *MonoBitmap) Invert(area image.Rectangle) {
-	//Naive solution. TODO later faster solution
-
-	//TODO check limits
-	x0 := min(max(0, area.Min.X), p.W-1)
-	x1 := min(max(0, area.Max.X), p.W-1)
-
-	y0 := min(max(0, area.Min.Y), p.H-1)
-	y1 := min(max(0, area.Max.Y), p.H-1)
-
-	for x := x0; x <= x1; x++ {
-		for y := y0; y <= y1; y++ {
-			p.SetPixNoCheck(x, y, !p.GetPixNoCheck(x, y))
-		}
+	area = image.Rectangle{Min: area.Min, Max: area.Max.Add(image.Pt(1, 1))}.Intersect(p.LocalBounds())
+	if area.Empty() {
+		return
+	}
+	p.markDirty(area)
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		bit := p.rowBit(y) + area.Min.X
+		blitBits(p.Pix, bit, p.Pix, bit, area.Dx(), ROP_NOTCOPY) //Same alignment, each word is read before it is written
 	}
 }
 
@@ -329,48 +315,59 @@ func (p *MonoBitmap) FlipH() {
 // +1=90 clockwise
 // -1=90 anticlockwise
 // +2=180 clockwise etc...
+// Rotation keeping size is done in place. 90 degree turn of non-square bitmap replaces Pix, so views of it are detached.
+// Non-square SubBitmap view can not change its size and is left unchanged
 func (p *MonoBitmap) Rotate90(turn90 int) {
 	angle := turn90 % 4
-	result := NewMonoBitmap(p.W, p.H, false)
+	var result MonoBitmap
 	switch angle {
 	case 0:
 		return //NOP
 	case 1, -3:
-		result.W = p.H
-		result.H = p.W
+		result = NewMonoBitmap(p.H, p.W, false)
 		for x := 0; x < p.W; x++ {
 			for y := 0; y < p.H; y++ {
 				result.SetPix(p.H-y-1, x, p.GetPixNoCheck(x, y))
 			}
 		}
 	case 2, -2:
+		result = NewMonoBitmap(p.W, p.H, false)
 		for x := 0; x < p.W; x++ {
 			for y := 0; y < p.H; y++ {
 				result.SetPix(p.W-x-1, p.H-y-1, p.GetPixNoCheck(x, y))
 			}
 		}
 	case 3, -1:
-		result.W = p.H
-		result.H = p.W
+		result = NewMonoBitmap(p.H, p.W, false)
 		for x := 0; x < p.W; x++ {
 			for y := 0; y < p.H; y++ {
 				result.SetPix(y, p.W-x-1, p.GetPixNoCheck(x, y))
 			}
 		}
 	}
-	p.W = result.W
-	p.H = result.H
-	p.Pix = result.Pix
+	if result.W == p.W && result.H == p.H {
+		p.DrawBitmapOp(result, result.LocalBounds(), image.Point{}, ROP_COPY)
+		return
+	}
+	if p.view {
+		return
+	}
+	result.dirty = p.dirty
+	*p = result
+	p.markDirty(p.LocalBounds())
 }
 
 // Bresenham's line, copied from http://41j.com/blog/2012/09/bresenhams-line-drawing-algorithm-implemetations-in-go-and-c/
 func (p *MonoBitmap) Line(p0In image.Point, p1In image.Point, value bool) {
 
-	bou := p.Bounds()
+	bou := p.LocalBounds()
 	bou.Max.X--
 	bou.Max.Y--
 	p0, p1 := ClipLine(p0In, p1In, bou)
 	//fmt.Printf("p0 %#v  ->  %#v\np1 %#v  ->  %#v\n", p0In, p0, p1In, p1)
+	if p0 == nil { //Whole line is outside
+		return
+	}
 
 	//TODO CLIP INTO VIEWPORT!
 	var cx int32 = int32(p0.X)
@@ -420,7 +417,7 @@ func (p *MonoBitmap) Line(p0In image.Point, p1In image.Point, value bool) {
 // Horizontal line for filling
 
 func (p *MonoBitmap) Hline_(x0 int, x1 int, y int, value bool) {
-	if y < 0 || p.H <= y {
+	if y < 0 || p.H <= y || p.W == 0 {
 		return
 	}
 	start := min(p.W-1, max(0, x0))
@@ -437,22 +434,19 @@ func (p *MonoBitmap) Hline(x0 int, x1 int, y int, value bool) {
 	}
 	start := min(p.W, max(0, x0))
 	end := min(p.W, max(0, x1+1))
+	if end <= start {
+		return
+	}
+	p.markDirty(image.Rect(start, y, end, y+1))
 
-	i0 := start + p.W*y
-	i1 := end + p.W*y
+	i0 := p.rowBit(y) + start
+	i1 := p.rowBit(y) + end - 1 //Last pixel included
 
 	index0 := i0 >> 5
 	index1 := i1 >> 5
 
-	shift0 := uint32(i0 % 32)
-	shift1 := uint32(i1 % 32)
-
-	bm0 := uint32(0xFFFFFFFF) << shift0
-	bm1 := ^(uint32(0xFFFFFFFF) << shift1)
-
-	/*fmt.Printf("index0=%v index1=%v\nbm0=%s\nbm1=%s",
-	index0, index1,
-	strconv.FormatInt(int64(bm0), 2), strconv.FormatInt(int64(bm1), 2))*/
+	bm0 := uint32(0xFFFFFFFF) << uint32(i0&31)
+	bm1 := uint32(0xFFFFFFFF) >> uint32(31-(i1&31))
 
 	if index0 == index1 { //short case
 		bm := bm0 & bm1
@@ -483,7 +477,10 @@ func (p *MonoBitmap) Hline(x0 int, x1 int, y int, value bool) {
 }
 
 func (p *MonoBitmap) Vline(x int, y0 int, y1 int, value bool) {
-	for i := y0; i <= y1; i++ {
+	if x < 0 || p.W <= x {
+		return
+	}
+	for i := max(y0, 0); i <= min(y1, p.H-1); i++ {
 		p.SetPixNoCheck(x, i, value)
 	}
 }
@@ -541,147 +538,67 @@ func (p *MonoBitmap) Circle(p0 image.Point, r int, value bool) {
 
 // Gets pixel. Returns false if out of range
 func (p *MonoBitmap) GetPix(x int, y int) bool {
-	index := (x + p.W*y) / 32
-	alabitit := uint32((x + p.W*y) % 32)
-	//alabitit:=byte(x)&7
-	bittimaski := uint32(1 << alabitit)
-	if index < len(p.Pix) {
-		return ((p.Pix[index] & bittimaski) > 0)
+	if (x < 0) || (y < 0) || (p.W <= x) || (p.H <= y) {
+		return false
 	}
-	return false
+	return p.GetPixNoCheck(x, y)
 }
 
 func (p *MonoBitmap) GetPixNoCheck(x int, y int) bool {
-	index := (x + p.W*y) / 32
-	alabitit := uint32((x + p.W*y) % 32)
-	bittimaski := uint32(1 << alabitit)
-
-	return ((p.Pix[index] & bittimaski) > 0)
-
+	i := p.rowBit(y) + x
+	bittimaski := uint32(1 << uint32(i&31))
+	return ((p.Pix[i>>5] & bittimaski) > 0)
 }
 
-// TODO BUG: does not work if not div by 8
 func (p *MonoBitmap) SetPix(x int, y int, value bool) {
-	i := x + p.W*y
-	index := i / 32
-	bittimaski := uint32(1 << uint32(i%32))
-
 	if (0 <= x) && (0 <= y) && (x < p.W) && (y < p.H) {
-		if value {
-			p.Pix[index] |= bittimaski
-		} else {
-			p.Pix[index] &= (bittimaski ^ uint32(0xFFFFFFFF))
-		}
+		p.SetPixNoCheck(x, y, value)
 	}
 }
 
 func (p *MonoBitmap) SetPixNoCheck(x int, y int, value bool) {
-	i := x + p.W*y
-	index := i / 32
-	bittimaski := uint32(1 << uint32(i%32))
+	i := p.rowBit(y) + x
+	index := i >> 5
+	bittimaski := uint32(1 << uint32(i&31))
+	if p.dirty != nil {
+		p.markDirty(image.Rect(x, y, x+1, y+1))
+	}
 
 	if value {
 		p.Pix[index] |= bittimaski
 	} else {
 		p.Pix[index] &= (bittimaski ^ uint32(0xFFFFFFFF))
 	}
-
 }
 
 // Draws source bitmap on bitmap
 // drawTrue, draw when point value is true
-// drawFalse,  draw when point value is true
+// drawFalse,  draw when point value is false
+// invert, drawn value is inverted
+// Pixels that are not drawn keep their value, so drawTrue alone sets target where source is on (clears with invert)
+// Before word parallel version drawTrue alone worked as if invert was swapped and drawFalse alone drew nothing
+// Drawing is done with word parallel DrawBitmapOp
 func (p *MonoBitmap) DrawBitmap(source MonoBitmap, sourceArea image.Rectangle, targetCorner image.Point, drawTrue bool, drawFalse bool, invert bool) {
-	if !drawTrue && !drawFalse {
-		return //NOP operation
-	}
-	//TODO naive solution, make optimized later
-	dx := sourceArea.Dx()
-	dy := sourceArea.Dy()
-
-	targetEnd := image.Point{X: min(p.W, targetCorner.X+dx), Y: min(p.H, targetCorner.Y+dy)}
-
-	x0 := min(max(0, targetCorner.X), p.W-1)
-	y0 := min(max(0, targetCorner.Y), p.H-1)
-
-	if drawTrue && drawFalse {
+	switch {
+	case drawTrue && drawFalse:
 		if invert {
-			for x := x0; x < targetEnd.X; x++ {
-				for y := y0; y < targetEnd.Y; y++ {
-					v := source.GetPixNoCheck(x-x0+sourceArea.Min.X, y-y0+sourceArea.Min.Y)
-					p.SetPixNoCheck(x, y, !v) //TODO copy byte by byte
-				}
-			}
+			p.DrawBitmapOp(source, sourceArea, targetCorner, ROP_NOTCOPY)
 		} else {
-			for x := x0; x < targetEnd.X; x++ {
-				for y := y0; y < targetEnd.Y; y++ {
-					v := source.GetPixNoCheck(x-x0+sourceArea.Min.X, y-y0+sourceArea.Min.Y)
-					p.SetPixNoCheck(x, y, v) //TODO copy byte by byte
-				}
-			}
+			p.DrawBitmapOp(source, sourceArea, targetCorner, ROP_COPY)
 		}
-		return
-	}
-	//Now only one
-	if drawTrue {
+	case drawTrue:
 		if invert {
-			for x := x0; x < targetEnd.X; x++ {
-				for y := y0; y < targetEnd.Y; y++ {
-					v := source.GetPixNoCheck(x-x0+sourceArea.Min.X, y-y0+sourceArea.Min.Y)
-					if v {
-						p.SetPixNoCheck(x, y, true) //TODO OPTIMIZE with special true and false operations? or does compiler that?
-					}
-				}
-			}
+			p.DrawBitmapOp(source, sourceArea, targetCorner, ROP_ANDNOT)
 		} else {
-			for x := x0; x < targetEnd.X; x++ {
-				for y := y0; y < targetEnd.Y; y++ {
-					v := source.GetPixNoCheck(x-x0+sourceArea.Min.X, y-y0+sourceArea.Min.Y)
-					if v {
-						p.SetPixNoCheck(x, y, false)
-					}
-				}
-			}
+			p.DrawBitmapOp(source, sourceArea, targetCorner, ROP_OR)
 		}
-		return
-	}
-	//Now draw on false
-	if drawTrue {
+	case drawFalse:
 		if invert {
-			for x := x0; x < targetEnd.X; x++ {
-				for y := y0; y < targetEnd.Y; y++ {
-					v := source.GetPixNoCheck(x-x0+sourceArea.Min.X, y-y0+sourceArea.Min.Y)
-					if !v {
-						p.SetPixNoCheck(x, y, true)
-					}
-				}
-			}
+			p.DrawBitmapOp(source, sourceArea, targetCorner, ROP_ORNOT)
 		} else {
-			for x := x0; x < targetEnd.X; x++ {
-				for y := y0; y < targetEnd.Y; y++ {
-					v := source.GetPixNoCheck(x-x0+sourceArea.Min.X, y-y0+sourceArea.Min.Y)
-					if !v {
-						p.SetPixNoCheck(x, y, false)
-					}
-				}
-			}
+			p.DrawBitmapOp(source, sourceArea, targetCorner, ROP_AND)
 		}
-		return
 	}
-
-	/*
-		for x := x0; x < targetEnd.X; x++ {
-			for y := targetCorner.Y; y < targetEnd.Y; y++ {
-				v := source.GetPix(x-targetCorner.X+sourceArea.Min.X, y-targetCorner.Y+sourceArea.Min.Y)
-				if (v) && (drawTrue) {
-					p.SetPix(x, y, !invert) //TODO copy byte by byte
-				}
-				if (!v) && (drawFalse) {
-					p.SetPix(x, y, invert)
-				}
-			}
-		}
-	*/
 }
 
 // Prints message on screen.Creates new lines on \n
@@ -715,7 +632,7 @@ func (p *MonoBitmap) Print(text string, font MonoFont, lineSpacing int, gap int,
 			}
 		}
 		if (!wrap) || (x+f.W <= area.Max.X) {
-			p.DrawBitmap(f, f.Bounds(), image.Point{X: x, Y: y}, drawTrue, drawFalse, invert)
+			p.DrawBitmap(f, f.LocalBounds(), image.Point{X: x, Y: y}, drawTrue, drawFalse, invert)
 			result.Max.X = max(result.Max.X, x+f.W)
 			result.Max.Y = max(result.Max.Y, y+f.H)
 			x += f.W + gap
@@ -52,6 +52,57 @@ func BenchmarkVlines(bench *testing.B) {
 	}
 }
 
+// Naive pixel by pixel reference for blitter benchmark and DrawBitmap modes
+func drawBitmapPixelByPixel(target *gomonochromebitmap.MonoBitmap, source gomonochromebitmap.MonoBitmap, corner image.Point, drawTrue bool, drawFalse bool, invert bool) {
+	for y := 0; y < source.H; y++ {
+		for x := 0; x < source.W; x++ {
+			v := source.GetPixNoCheck(x, y)
+			if (v && drawTrue) || (!v && drawFalse) {
+				target.SetPix(corner.X+x, corner.Y+y, v != invert)
+			}
+		}
+	}
+}
+
+// BenchmarkDrawBitmapNaive-16    	     522	   2346937 ns/op	       0 B/op	       0 allocs/op
+func BenchmarkDrawBitmapNaive(bench *testing.B) {
+	b := gomonochromebitmap.NewMonoBitmap(640, 480, false)
+	glyph := gomonochromebitmap.GetFont_11x16()['A']
+	bench.ResetTimer()
+	for range bench.N {
+		for y := 0; y < b.H; y += glyph.H {
+			for x := 0; x < b.W; x += glyph.W + 1 {
+				drawBitmapPixelByPixel(&b, glyph, image.Point{X: x, Y: y}, true, true, false)
+			}
+		}
+	}
+}
+
+// BenchmarkDrawBitmap-16    	    3632	    450672 ns/op	       0 B/op	       0 allocs/op
+func BenchmarkDrawBitmap(bench *testing.B) {
+	b := gomonochromebitmap.NewMonoBitmap(640, 480, false)
+	glyph := gomonochromebitmap.GetFont_11x16()['A']
+	bench.ResetTimer()
+	for range bench.N {
+		for y := 0; y < b.H; y += glyph.H {
+			for x := 0; x < b.W; x += glyph.W + 1 {
+				b.DrawBitmap(glyph, glyph.Bounds(), image.Point{X: x, Y: y}, true, true, false)
+			}
+		}
+	}
+}
+
+// Large unaligned copy, like scrolling
+// BenchmarkDrawBitmapLarge-16    	   13898	     79085 ns/op	       0 B/op	       0 allocs/op
+func BenchmarkDrawBitmapLarge(bench *testing.B) {
+	b := gomonochromebitmap.NewMonoBitmap(640, 480, false)
+	src := gomonochromebitmap.NewMonoBitmap(600, 400, true)
+	bench.ResetTimer()
+	for range bench.N {
+		b.DrawBitmapOp(src, src.Bounds(), image.Point{X: 13, Y: 7}, gomonochromebitmap.ROP_XOR)
+	}
+}
+
 func isVline(bm gomonochromebitmap.MonoBitmap, x int, y0 int, y1 int, value bool) error {
 	for i := y0; i <= y1; i++ {
 		if bm.GetPix(x, i) != value && i < bm.W {
@@ -196,13 +247,13 @@ func TestSimple(t *testing.T) {
 
 	//test1.FlipH()
 	test1.Invert(image.Rect(55, 35, 270, 350))
-	test2 := test1.GetView(128, 64, image.Point{X: 38, Y: 260}, 0, true)
+	test2, _, _ := test1.GetView(128, 64, image.Point{X: 38, Y: 260}, 0, true)
 
 	//Small image, chip8 example
 	chip8pic := gomonochromebitmap.NewMonoBitmap(64, 32, false)
 	chip8pic.SetPix(3, 5, true)
 	chip8pic.CircleFill(image.Point{X: 64, Y: 32}, 32, true)
-	test3 := chip8pic.GetView(128, 64, image.Point{X: 0, Y: 0}, 0, true)
+	test3, _, _ := chip8pic.GetView(128, 64, image.Point{X: 0, Y: 0}, 0, true)
 
 	pngimg, _, _ := image.Decode(imgfile)
 
@@ -283,3 +334,72 @@ func TestPlanarColors(t *testing.T) {
 	out.Close()
 }
 */
+
+func TestRowAlignedLayout(t *testing.T) {
+	b := gomonochromebitmap.NewMonoBitmap(33, 3, true)
+	if b.Stride != 2 || len(b.Pix) != 6 {
+		t.Fatalf("invalid stride %v or pix len %v", b.Stride, len(b.Pix))
+	}
+	for y := 0; y < b.H; y++ {
+		row := b.Row(y)
+		if row[0] != 0xFFFFFFFF || row[1] != 1 {
+			t.Errorf("row %v is %08X %08X, padding must stay clear", y, row[0], row[1])
+		}
+	}
+	if b.GetPix(33, 0) || b.GetPix(-1, 1) {
+		t.Errorf("out of range pixel must be false")
+	}
+}
+
+func TestFlatPixConversion(t *testing.T) {
+	w, h := 13, 7
+	flat := make([]uint32, w*h/32+1)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if (x*y+x)%3 == 0 {
+				i := x + w*y
+				flat[i/32] |= 1 << uint32(i%32)
+			}
+		}
+	}
+	b := gomonochromebitmap.NewMonoBitmapFromFlatPix(flat, w, h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if b.GetPix(x, y) != ((x*y+x)%3 == 0) {
+				t.Errorf("pixel %v,%v not converted", x, y)
+			}
+		}
+	}
+}
+
+func TestInvert(t *testing.T) {
+	b := gomonochromebitmap.NewMonoBitmap(70, 4, false)
+	b.Invert(image.Rect(-5, 1, 40, 2))
+	b.Invert(image.Rect(30, 2, 100, 10))
+	for y := 0; y < b.H; y++ {
+		for x := 0; x < b.W; x++ {
+			expected := (1 <= y && y <= 2 && x <= 40) != (2 <= y && 30 <= x)
+			if b.GetPix(x, y) != expected {
+				t.Fatalf("pixel %v,%v is %v", x, y, b.GetPix(x, y))
+			}
+		}
+	}
+	for y := 0; y < b.H; y++ {
+		if b.Row(y)[2]>>6 != 0 {
+			t.Errorf("padding on row %v changed", y)
+		}
+	}
+
+	//Empty bitmaps and areas outside do nothing
+	for _, size := range []image.Point{{0, 0}, {10, 0}, {0, 10}} {
+		empty := gomonochromebitmap.NewMonoBitmap(size.X, size.Y, false)
+		empty.Invert(empty.LocalBounds())
+		empty.Invert(image.Rect(-3, -3, 20, 20))
+	}
+	before := b.Clone()
+	b.Invert(image.Rect(70, 0, 80, 3))
+	b.Invert(image.Rect(-10, -10, -1, -1))
+	if !equalBitmaps(before, b) {
+		t.Errorf("invert outside changed bitmap")
+	}
+}
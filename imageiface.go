@@ -0,0 +1,61 @@
+/*
+Standard library image interfaces for MonoBitmap
+
+*MonoBitmap implements image.Image and draw.Image so image/draw, image/png
+etc... can operate on bitmap without creating RGBA copy
+
+Like on SubImage of image.RGBA, Bounds, At and Set of SubBitmap view use root
+bitmap coordinates. Drawing functions like SetPix and Fill use view's own
+coordinates where LocalBounds is 0,0...W,H
+*/
+package gomonochromebitmap
+
+import (
+	"image/color"
+	"image/draw"
+)
+
+var _ draw.Image = (*MonoBitmap)(nil)
+
+// MonoColor is color of single pixel, true when pixel is on. On is rendered as white and off as black
+type MonoColor bool
+
+func (c MonoColor) RGBA() (r, g, b, a uint32) {
+	if c {
+		return 0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF
+	}
+	return 0, 0, 0, 0xFFFF
+}
+
+// MonoModel converts any color to MonoColor. Pixel is on when luminance is at least half of maximum
+var MonoModel color.Model = color.ModelFunc(monoModel)
+
+func monoModel(c color.Color) color.Color {
+	if mc, ok := c.(MonoColor); ok {
+		return mc
+	}
+	r, g, b, _ := c.RGBA()
+	// Same weights than color.GrayModel uses
+	y := (19595*r + 38470*g + 7471*b + 1<<15) >> 16
+	return MonoColor(0x8000 <= y)
+}
+
+// ColorModel returns MonoModel
+func (p *MonoBitmap) ColorModel() color.Model {
+	return MonoModel
+}
+
+// At returns color of pixel on root bitmap coordinates. Pixels outside of Bounds are off
+func (p *MonoBitmap) At(x int, y int) color.Color {
+	return MonoColor(p.GetPix(x-p.Origin.X, y-p.Origin.Y))
+}
+
+// Set converts color with MonoModel and sets pixel on root bitmap coordinates. Pixels outside of Bounds are ignored
+func (p *MonoBitmap) Set(x int, y int, c color.Color) {
+	p.SetPix(x-p.Origin.X, y-p.Origin.Y, bool(MonoModel.Convert(c).(MonoColor)))
+}
+
+// Opaque reports that bitmap has no transparent pixels. Encoders use this for picking more compact format
+func (p *MonoBitmap) Opaque() bool {
+	return true
+}
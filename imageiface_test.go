@@ -0,0 +1,109 @@
+package gomonochromebitmap_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestMonoModel(t *testing.T) {
+	testCases := []struct {
+		c        color.Color
+		expected bool
+	}{
+		{color.White, true},
+		{color.Black, false},
+		{color.RGBA{R: 255, G: 0, B: 0, A: 255}, false},
+		{color.RGBA{R: 0, G: 255, B: 0, A: 255}, true},
+		{color.Gray{Y: 127}, false},
+		{color.Gray{Y: 128}, true},
+		{color.Transparent, false},
+		{gomonochromebitmap.MonoColor(true), true},
+	}
+	for i, tc := range testCases {
+		v := gomonochromebitmap.MonoModel.Convert(tc.c).(gomonochromebitmap.MonoColor)
+		if bool(v) != tc.expected {
+			t.Errorf("case %v: color %#v converted to %v", i, tc.c, v)
+		}
+	}
+}
+
+func TestImageInterface(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(40, 20, false)
+
+	src := image.NewGray(image.Rect(0, 0, 10, 10))
+	draw.Draw(src, src.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(&bm, image.Rect(5, 5, 15, 15), src, image.Point{}, draw.Src)
+
+	if !bm.GetPix(5, 5) || !bm.GetPix(14, 14) || bm.GetPix(4, 5) || bm.GetPix(15, 14) {
+		t.Errorf("draw.Draw did not set pixels")
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, &bm); err != nil {
+		t.Fatal(err)
+	}
+	decoded, errDecode := png.Decode(&buf)
+	if errDecode != nil {
+		t.Fatal(errDecode)
+	}
+	if decoded.Bounds() != bm.Bounds() {
+		t.Fatalf("bounds changed %v", decoded.Bounds())
+	}
+	for y := 0; y < bm.H; y++ {
+		for x := 0; x < bm.W; x++ {
+			v := gomonochromebitmap.MonoModel.Convert(decoded.At(x, y)).(gomonochromebitmap.MonoColor)
+			if bool(v) != bm.GetPix(x, y) {
+				t.Errorf("png round trip failed at %v,%v", x, y)
+			}
+		}
+	}
+	if bm.At(-1, 0) != gomonochromebitmap.MonoColor(false) {
+		t.Errorf("outside pixel must be off")
+	}
+}
+
+func TestImageInterfaceView(t *testing.T) {
+	//View uses root coordinates on image side, like SubImage of image.RGBA
+	root := gomonochromebitmap.NewMonoBitmap(40, 20, false)
+	view := root.SubBitmap(image.Rect(10, 4, 30, 16))
+	rgba := image.NewRGBA(root.Bounds())
+	sub := rgba.SubImage(image.Rect(10, 4, 30, 16))
+	if view.Bounds() != sub.Bounds() {
+		t.Fatalf("view bounds %v, RGBA sub image bounds %v", view.Bounds(), sub.Bounds())
+	}
+
+	draw.Draw(&view, image.Rect(0, 0, 12, 6), image.White, image.Point{}, draw.Src)
+	for y := 0; y < root.H; y++ {
+		for x := 0; x < root.W; x++ {
+			expected := 10 <= x && x < 12 && 4 <= y && y < 6
+			if root.GetPix(x, y) != expected {
+				t.Fatalf("draw.Draw on view differs at %v,%v", x, y)
+			}
+		}
+	}
+	if view.At(10, 4) != gomonochromebitmap.MonoColor(true) || view.At(12, 4) != gomonochromebitmap.MonoColor(false) || view.At(0, 0) != gomonochromebitmap.MonoColor(false) {
+		t.Errorf("At does not use root coordinates")
+	}
+	view.Set(29, 15, color.White)
+	if !view.GetPix(19, 11) {
+		t.Errorf("Set does not use root coordinates")
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, &view); err != nil {
+		t.Fatal(err)
+	}
+	decoded, errDecode := png.Decode(&buf)
+	if errDecode != nil {
+		t.Fatal(errDecode)
+	}
+	if decoded.Bounds().Size() != view.LocalBounds().Size() {
+		t.Errorf("encoded size %v", decoded.Bounds())
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Binary morphology
+
+Structuring element (kernel) is MonoBitmap, its origin is at center pixel
+(W/2, H/2). Operations shift whole bitmap once for each on pixel of kernel
+and combine shifted copies with word parallel raster operations.
+
+Pixels outside bitmap are off for dilation and on for erosion. Then opening
+and erosion do not eat shapes touching edges of bitmap. Closing is done on
+bitmap with margin, so it does not grow shapes towards edges.
+
+All operations return new bitmap and keep source unchanged.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+	"math"
+)
+
+// KernelSquare returns size*size square
+func KernelSquare(size int) MonoBitmap {
+	return NewMonoBitmap(size, size, true)
+}
+
+// KernelCross returns plus sign with size*size bounding box
+func KernelCross(size int) MonoBitmap {
+	result := NewMonoBitmap(size, size, false)
+	result.Hline(0, size-1, size/2, true)
+	result.Vline(size/2, 0, size-1, true)
+	return result
+}
+
+// KernelDisk returns disk with radius r. Size is 2r+1
+func KernelDisk(r int) MonoBitmap {
+	result := NewMonoBitmap(2*r+1, 2*r+1, false)
+	for y := -r; y <= r; y++ {
+		half := int(math.Sqrt(float64(r*r + r - y*y))) //r*r+r rounds disk outline nicer than r*r
+		result.Hline(r-half, r+half, r+y, true)
+	}
+	return result
+}
+
+// kernelOffsets lists on pixels of kernel relative to its origin
+func kernelOffsets(kernel *MonoBitmap) []image.Point {
+	var result []image.Point
+	origin := image.Pt(kernel.W/2, kernel.H/2)
+	for y := 0; y < kernel.H; y++ {
+		for x := 0; x < kernel.W; x++ {
+			if kernel.GetPixNoCheck(x, y) {
+				result = append(result, image.Pt(x, y).Sub(origin))
+			}
+		}
+	}
+	return result
+}
+
+// Dilate sets pixel when any on pixel of kernel placed on it hits on pixel. Grows shapes
+func (p *MonoBitmap) Dilate(kernel *MonoBitmap) MonoBitmap {
+	result := NewMonoBitmap(p.W, p.H, false)
+	for _, d := range kernelOffsets(kernel) {
+		result.DrawBitmapOp(*p, p.LocalBounds(), d, ROP_OR)
+	}
+	return result
+}
+
+// Erode keeps pixel when all on pixels of kernel placed on it hit on pixels. Shrinks shapes
+func (p *MonoBitmap) Erode(kernel *MonoBitmap) MonoBitmap {
+	result := NewMonoBitmap(p.W, p.H, true)
+	for _, d := range kernelOffsets(kernel) {
+		result.DrawBitmapOp(*p, p.LocalBounds(), image.Point{}.Sub(d), ROP_AND)
+	}
+	return result
+}
+
+// Open is erosion followed by dilation. Removes details smaller than kernel
+func (p *MonoBitmap) Open(kernel *MonoBitmap) MonoBitmap {
+	eroded := p.Erode(kernel)
+	return eroded.Dilate(kernel)
+}
+
+// Close is dilation followed by erosion. Fills gaps smaller than kernel. Done with margin, so dilation over edges does not stick on edges
+func (p *MonoBitmap) Close(kernel *MonoBitmap) MonoBitmap {
+	margin := max(kernel.W, kernel.H)
+	padded := NewMonoBitmap(p.W+2*margin, p.H+2*margin, false)
+	padded.DrawBitmapOp(*p, p.LocalBounds(), image.Pt(margin, margin), ROP_COPY)
+	dilated := padded.Dilate(kernel)
+	closed := dilated.Erode(kernel)
+	result := NewMonoBitmap(p.W, p.H, false)
+	result.DrawBitmapOp(closed, image.Rect(margin, margin, margin+p.W, margin+p.H), image.Point{}, ROP_COPY)
+	return result
+}
+
+// HitOrMiss keeps pixels where hit kernel matches on pixels and miss kernel matches off pixels
+func (p *MonoBitmap) HitOrMiss(hit *MonoBitmap, miss *MonoBitmap) MonoBitmap {
+	result := p.Erode(hit)
+	complement := NewMonoBitmap(p.W, p.H, false)
+	complement.DrawBitmapOp(*p, p.LocalBounds(), image.Point{}, ROP_NOTCOPY)
+	misses := complement.Erode(miss)
+	result.DrawBitmapOp(misses, misses.LocalBounds(), image.Point{}, ROP_AND)
+	return result
+}
+
+// Gradient is difference of dilation and erosion. Gives outline on both sides of shape edges
+func (p *MonoBitmap) Gradient(kernel *MonoBitmap) MonoBitmap {
+	result := p.Dilate(kernel)
+	eroded := p.Erode(kernel)
+	result.DrawBitmapOp(eroded, eroded.LocalBounds(), image.Point{}, ROP_ANDNOT)
+	return result
+}
+
+// Outline keeps pixels of shape that erosion removes. Gives inner outline
+func (p *MonoBitmap) Outline(kernel *MonoBitmap) MonoBitmap {
+	result := p.Clone()
+	eroded := p.Erode(kernel)
+	result.DrawBitmapOp(eroded, eroded.LocalBounds(), image.Point{}, ROP_ANDNOT)
+	return result
+}
+
+// TopHat keeps details that opening removes. Small bright spots and thin lines
+func (p *MonoBitmap) TopHat(kernel *MonoBitmap) MonoBitmap {
+	result := p.Clone()
+	opened := p.Open(kernel)
+	result.DrawBitmapOp(opened, opened.LocalBounds(), image.Point{}, ROP_ANDNOT)
+	return result
+}
+
+// BlackHat keeps gaps that closing fills
+func (p *MonoBitmap) BlackHat(kernel *MonoBitmap) MonoBitmap {
+	result := p.Close(kernel)
+	result.DrawBitmapOp(*p, p.LocalBounds(), image.Point{}, ROP_ANDNOT)
+	return result
+}
+
+// thinningKernels returns hit and miss pairs of Golay L elements on all 8 directions
+func thinningKernels() [][2]MonoBitmap {
+	edgeHit, edgeMiss := NewMonoBitmap(3, 3, false), NewMonoBitmap(3, 3, false)
+	edgeHit.SetPix(1, 1, true)
+	edgeHit.Hline(0, 2, 2, true)
+	edgeMiss.Hline(0, 2, 0, true)
+
+	cornerHit, cornerMiss := NewMonoBitmap(3, 3, false), NewMonoBitmap(3, 3, false)
+	cornerHit.Hline(1, 2, 1, true)
+	cornerHit.SetPix(1, 2, true)
+	cornerMiss.Hline(0, 1, 0, true)
+	cornerMiss.SetPix(0, 1, true)
+
+	var result [][2]MonoBitmap
+	for turn := 0; turn < 4; turn++ {
+		result = append(result, [2]MonoBitmap{edgeHit.Clone(), edgeMiss.Clone()}, [2]MonoBitmap{cornerHit.Clone(), cornerMiss.Clone()})
+		for _, k := range []*MonoBitmap{&edgeHit, &edgeMiss, &cornerHit, &cornerMiss} {
+			k.Rotate90(1)
+		}
+	}
+	return result
+}
+
+// Thin removes pixels from shape edges until only one pixel wide 8-connected lines are left (skeleton). Iterations limits passes over all directions, 0 runs until result is stable
+func (p *MonoBitmap) Thin(iterations int) MonoBitmap {
+	result := p.Clone()
+	kernels := thinningKernels()
+	for i := 0; iterations <= 0 || i < iterations; i++ {
+		changed := false
+		for _, k := range kernels {
+			removed := result.HitOrMiss(&k[0], &k[1])
+			if removed.countBlock(removed.LocalBounds()) == 0 {
+				continue
+			}
+			changed = true
+			result.DrawBitmapOp(removed, removed.LocalBounds(), image.Point{}, ROP_ANDNOT)
+		}
+		if !changed {
+			break
+		}
+	}
+	return result
+}
+
+// Skeleton is morphological (Lantuejoul) skeleton. It is union of details that opening removes from successive erosions. Shape can be reconstructed from it, but lines are not always connected like on Thin
+func (p *MonoBitmap) Skeleton(kernel *MonoBitmap) MonoBitmap {
+	result := NewMonoBitmap(p.W, p.H, false)
+	eroded := p.Clone()
+	for eroded.countBlock(eroded.LocalBounds()) != 0 {
+		details := eroded.TopHat(kernel)
+		result.DrawBitmapOp(details, details.LocalBounds(), image.Point{}, ROP_OR)
+		next := eroded.Erode(kernel)
+		if next.countBlock(next.LocalBounds()) == eroded.countBlock(eroded.LocalBounds()) { //Erosion does not proceed, when shape fills whole bitmap
+			result.DrawBitmapOp(next, next.LocalBounds(), image.Point{}, ROP_OR)
+			break
+		}
+		eroded = next
+	}
+	return result
+}
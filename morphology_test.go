@@ -0,0 +1,225 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+// refMorphology is pixel by pixel dilation or erosion with same edge rules
+func refMorphology(src gomonochromebitmap.MonoBitmap, kernel gomonochromebitmap.MonoBitmap, erode bool) gomonochromebitmap.MonoBitmap {
+	result := gomonochromebitmap.NewMonoBitmap(src.W, src.H, false)
+	for y := 0; y < src.H; y++ {
+		for x := 0; x < src.W; x++ {
+			value := erode
+			for ky := 0; ky < kernel.H; ky++ {
+				for kx := 0; kx < kernel.W; kx++ {
+					if !kernel.GetPixNoCheck(kx, ky) {
+						continue
+					}
+					dx, dy := kx-kernel.W/2, ky-kernel.H/2
+					if erode {
+						sx, sy := x+dx, y+dy
+						inside := 0 <= sx && 0 <= sy && sx < src.W && sy < src.H
+						if inside && !src.GetPixNoCheck(sx, sy) {
+							value = false
+						}
+					} else if src.GetPix(x-dx, y-dy) {
+						value = true
+					}
+				}
+			}
+			result.SetPixNoCheck(x, y, value)
+		}
+	}
+	return result
+}
+
+func TestMorphologyReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(32))
+	for i := 0; i < 20; i++ {
+		src := randomBitmap(rnd, 20+rnd.Intn(60), 1+rnd.Intn(20))
+		kernel := randomBitmap(rnd, 1+rnd.Intn(5), 1+rnd.Intn(5))
+		if result := src.Dilate(&kernel); !equalBitmaps(refMorphology(src, kernel, false), result) {
+			t.Errorf("dilate %v differs", i)
+		}
+		if result := src.Erode(&kernel); !equalBitmaps(refMorphology(src, kernel, true), result) {
+			t.Errorf("erode %v differs", i)
+		}
+	}
+}
+
+func TestKernels(t *testing.T) {
+	cross := gomonochromebitmap.KernelCross(3)
+	if !equalBitmaps(parseBitmap(".#.", "###", ".#."), cross) {
+		t.Errorf("cross %v", bitmapString(cross))
+	}
+	disk := gomonochromebitmap.KernelDisk(3)
+	expected := parseBitmap(
+		"..###..",
+		".#####.",
+		"#######",
+		"#######",
+		"#######",
+		".#####.",
+		"..###..",
+	)
+	if !equalBitmaps(expected, disk) {
+		t.Errorf("disk %v", bitmapString(disk))
+	}
+	if square := gomonochromebitmap.KernelSquare(4); countOn(square) != 16 {
+		t.Errorf("square")
+	}
+}
+
+func TestOpenClose(t *testing.T) {
+	src := parseBitmap(
+		"..........",
+		".#........",
+		"....####..",
+		"....#.##..",
+		"....####..",
+		"#.........",
+		"#.........",
+	)
+	square := gomonochromebitmap.KernelSquare(3)
+	closed := src.Close(&square)
+	expected := parseBitmap(
+		"..........",
+		".#........",
+		"....####..",
+		"....####..",
+		"....####..",
+		"#.........",
+		"#.........",
+	)
+	if !equalBitmaps(expected, closed) {
+		t.Errorf("close %v", bitmapString(closed))
+	}
+	opened := closed.Open(&square)
+	expected = parseBitmap(
+		"..........",
+		"..........",
+		"....####..",
+		"....####..",
+		"....####..",
+		"..........",
+		"..........",
+	)
+	if !equalBitmaps(expected, opened) {
+		t.Errorf("open %v", bitmapString(opened))
+	}
+
+	//Shape touching edge is not eaten
+	full := gomonochromebitmap.NewMonoBitmap(8, 8, true)
+	if result := full.Open(&square); !equalBitmaps(full, result) {
+		t.Errorf("open ate edges")
+	}
+}
+
+func TestHitOrMiss(t *testing.T) {
+	src := parseBitmap(
+		"......",
+		".#..##",
+		"......",
+		"...#..",
+	)
+	hit := parseBitmap("...", ".#.", "...")
+	miss := parseBitmap("###", "#.#", "###")
+	isolated := src.HitOrMiss(&hit, &miss)
+	expected := parseBitmap(
+		"......",
+		".#....",
+		"......",
+		"...#..",
+	)
+	if !equalBitmaps(expected, isolated) {
+		t.Errorf("hit or miss %v", bitmapString(isolated))
+	}
+}
+
+func TestOutlineAndTopHat(t *testing.T) {
+	src := parseBitmap(
+		".......",
+		".#####.",
+		".#####.",
+		".#####.",
+		".......",
+		"######.",
+		".......",
+	)
+	cross := gomonochromebitmap.KernelCross(3)
+	outline := src.Outline(&cross)
+	expected := parseBitmap(
+		".......",
+		".#####.",
+		".#...#.",
+		".#####.",
+		".......",
+		"######.",
+		".......",
+	)
+	if !equalBitmaps(expected, outline) {
+		t.Errorf("outline %v", bitmapString(outline))
+	}
+	gradient := src.Gradient(&cross)
+	if !gradient.GetPix(1, 0) || !gradient.GetPix(1, 1) || gradient.GetPix(3, 2) || !gradient.GetPix(6, 5) {
+		t.Errorf("gradient %v", bitmapString(gradient))
+	}
+
+	square := gomonochromebitmap.KernelSquare(3)
+	tophat := src.TopHat(&square)
+	expected = parseBitmap(
+		".......",
+		".......",
+		".......",
+		".......",
+		".......",
+		"######.",
+		".......",
+	)
+	if !equalBitmaps(expected, tophat) {
+		t.Errorf("top hat %v", bitmapString(tophat))
+	}
+	ring := parseBitmap(
+		"#####",
+		"#...#",
+		"#####",
+	)
+	blackhat := ring.BlackHat(&square)
+	if countOn(blackhat) != 3 || !blackhat.GetPix(2, 1) {
+		t.Errorf("black hat %v", bitmapString(blackhat))
+	}
+}
+
+func TestThin(t *testing.T) {
+	src := gomonochromebitmap.NewMonoBitmap(40, 20, false)
+	src.Fill(image.Rect(5, 5, 34, 11), true)
+	thin := src.Thin(0)
+	for x := 8; x < 32; x++ {
+		n := 0
+		for y := 0; y < 20; y++ {
+			if thin.GetPix(x, y) {
+				n++
+			}
+		}
+		if n != 1 {
+			t.Fatalf("column %v has %v pixels %v", x, n, bitmapString(thin))
+		}
+	}
+	if result := thin.Thin(0); !equalBitmaps(thin, result) {
+		t.Errorf("thin result is not stable")
+	}
+
+	//Skeleton is inside shape and center of disk is on it
+	disk := gomonochromebitmap.KernelDisk(6)
+	cross := gomonochromebitmap.KernelCross(3)
+	skeleton := disk.Skeleton(&cross)
+	inside := skeleton.Clone()
+	inside.DrawBitmapOp(disk, disk.Bounds(), image.Point{}, gomonochromebitmap.ROP_ANDNOT)
+	if !skeleton.GetPix(6, 6) || countOn(inside) != 0 || countOn(skeleton) == countOn(disk) {
+		t.Errorf("skeleton %v", bitmapString(skeleton))
+	}
+}
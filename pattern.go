@@ -0,0 +1,167 @@
+/*
+Pattern fills
+
+On 1-bit display gray levels and textures are expressed as repeating patterns.
+Pattern is tile bitmap repeated over whole bitmap. Tile pixel 0,0 is on
+Origin, default is root bitmap pixel 0,0. Fills with same pattern and origin
+line up seamlessly, so adjacent shapes look like one continuous surface. This
+holds also for fills made on parent and sibling SubBitmap views.
+
+Stock patterns are classic 8x8 stipple brushes, given as one byte per row with
+most significant bit on left.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+)
+
+// Pattern is tile repeated over bitmap
+type Pattern struct {
+	Tile   MonoBitmap  // One period of pattern
+	Origin image.Point // Root bitmap pixel where tile pixel 0,0 is placed
+}
+
+// PatternStyle selects stock pattern
+type PatternStyle byte
+
+const (
+	PATTERN_GRAY12              PatternStyle = 0  // 12.5% of pixels on
+	PATTERN_GRAY25              PatternStyle = 1  // 25% of pixels on
+	PATTERN_GRAY50              PatternStyle = 2  // 50% of pixels on, one pixel checkerboard
+	PATTERN_GRAY75              PatternStyle = 3  // 75% of pixels on
+	PATTERN_HATCH_HORIZONTAL    PatternStyle = 4  // Horizontal lines
+	PATTERN_HATCH_VERTICAL      PatternStyle = 5  // Vertical lines
+	PATTERN_HATCH_DIAGONAL      PatternStyle = 6  // Lines rising to right /
+	PATTERN_HATCH_BACKDIAGONAL  PatternStyle = 7  // Lines falling to right \
+	PATTERN_HATCH_CROSS         PatternStyle = 8  // Horizontal and vertical lines
+	PATTERN_HATCH_DIAGONALCROSS PatternStyle = 9  // Both diagonals
+	PATTERN_CHECKERBOARD        PatternStyle = 10 // 4x4 pixel squares
+)
+
+var stockPatterns = map[PatternStyle][8]byte{
+	PATTERN_GRAY12:              {0x88, 0x00, 0x22, 0x00, 0x88, 0x00, 0x22, 0x00},
+	PATTERN_GRAY25:              {0x88, 0x22, 0x88, 0x22, 0x88, 0x22, 0x88, 0x22},
+	PATTERN_GRAY50:              {0xAA, 0x55, 0xAA, 0x55, 0xAA, 0x55, 0xAA, 0x55},
+	PATTERN_GRAY75:              {0x77, 0xDD, 0x77, 0xDD, 0x77, 0xDD, 0x77, 0xDD},
+	PATTERN_HATCH_HORIZONTAL:    {0xFF, 0x00, 0x00, 0x00, 0xFF, 0x00, 0x00, 0x00},
+	PATTERN_HATCH_VERTICAL:      {0x88, 0x88, 0x88, 0x88, 0x88, 0x88, 0x88, 0x88},
+	PATTERN_HATCH_DIAGONAL:      {0x11, 0x22, 0x44, 0x88, 0x11, 0x22, 0x44, 0x88},
+	PATTERN_HATCH_BACKDIAGONAL:  {0x88, 0x44, 0x22, 0x11, 0x88, 0x44, 0x22, 0x11},
+	PATTERN_HATCH_CROSS:         {0xFF, 0x88, 0x88, 0x88, 0xFF, 0x88, 0x88, 0x88},
+	PATTERN_HATCH_DIAGONALCROSS: {0x99, 0x66, 0x66, 0x99, 0x99, 0x66, 0x66, 0x99},
+	PATTERN_CHECKERBOARD:        {0xF0, 0xF0, 0xF0, 0xF0, 0x0F, 0x0F, 0x0F, 0x0F},
+}
+
+// NewPattern creates pattern from tile, anchored to root bitmap pixel 0,0
+func NewPattern(tile MonoBitmap) Pattern {
+	return Pattern{Tile: tile}
+}
+
+// NewPattern8x8 creates pattern from 8x8 brush, one byte per row and most significant bit on left
+func NewPattern8x8(rows [8]byte) Pattern {
+	tile := NewMonoBitmap(8, 8, false)
+	for y, row := range rows {
+		for x := 0; x < 8; x++ {
+			tile.SetPixNoCheck(x, y, row&(0x80>>x) != 0)
+		}
+	}
+	return NewPattern(tile)
+}
+
+// StockPattern returns built in pattern. Unknown style gives empty pattern
+func StockPattern(style PatternStyle) Pattern {
+	return NewPattern8x8(stockPatterns[style])
+}
+
+// Anchored returns copy of pattern with tile pixel 0,0 on origin. Use widget corner on root bitmap as origin when pattern must move with widget
+func (a Pattern) Anchored(origin image.Point) Pattern {
+	a.Origin = origin
+	return a
+}
+
+// tileCoord wraps bitmap coordinate into tile, also on left and above origin
+func tileCoord(v int, origin int, size int) int {
+	v = (v - origin) % size
+	if v < 0 {
+		v += size
+	}
+	return v
+}
+
+// At returns pattern value on root bitmap pixel x,y. Pattern with empty tile is off everywhere
+func (a *Pattern) At(x int, y int) bool {
+	if a.Tile.W == 0 || a.Tile.H == 0 {
+		return false
+	}
+	return a.Tile.GetPixNoCheck(tileCoord(x, a.Origin.X, a.Tile.W), tileCoord(y, a.Origin.Y, a.Tile.H))
+}
+
+// patternHline combines pattern to pixels x0...x1 (inclusive) on row y, 32 pixels at time
+func (p *MonoBitmap) patternHline(x0 int, x1 int, y int, pattern *Pattern, op RasterOp) {
+	if y < 0 || p.H <= y || pattern.Tile.W == 0 || pattern.Tile.H == 0 {
+		return
+	}
+	start := max(0, x0)
+	end := min(p.W, x1+1)
+	if end <= start {
+		return
+	}
+	p.markDirty(image.Rect(start, y, end, y+1))
+
+	ty := tileCoord(y+p.Origin.Y, pattern.Origin.Y, pattern.Tile.H)
+	tx := tileCoord(start+p.Origin.X, pattern.Origin.X, pattern.Tile.W)
+	bit := p.rowBit(y) + start
+	for x := start; x < end; {
+		shift := uint32(bit & 31)
+		k := min(32-int(shift), end-x)
+		var s uint32
+		for i := 0; i < k; i++ {
+			if pattern.Tile.GetPixNoCheck(tx, ty) {
+				s |= 1 << (shift + uint32(i))
+			}
+			if tx++; tx == pattern.Tile.W {
+				tx = 0
+			}
+		}
+		mask := uint32(0xFFFFFFFF) >> uint32(32-k) << shift
+		p.Pix[bit>>5] = op.apply(p.Pix[bit>>5], s, mask)
+		bit += k
+		x += k
+	}
+}
+
+// paintMask copies pattern on pixels that are on in mask. Mask is same size as bitmap
+func (p *MonoBitmap) paintMask(mask *MonoBitmap, pattern *Pattern) {
+	for y := 0; y < mask.H; y++ {
+		mask.rowRuns(y, func(x0 int, x1 int) {
+			p.patternHline(x0, x1-1, y, pattern, ROP_COPY)
+		})
+	}
+}
+
+// FillPattern fills area with pattern. Area includes Max like on Fill
+func (p *MonoBitmap) FillPattern(area image.Rectangle, pattern Pattern) {
+	p.ApplyPattern(area, pattern, ROP_COPY)
+}
+
+// ApplyPattern combines pattern with existing pixels, for example ROP_ANDNOT with PATTERN_GRAY50 grays out disabled widget. Area includes Max like on Fill
+func (p *MonoBitmap) ApplyPattern(area image.Rectangle, pattern Pattern, op RasterOp) {
+	for y := area.Min.Y; y <= area.Max.Y; y++ {
+		p.patternHline(area.Min.X, area.Max.X, y, &pattern, op)
+	}
+}
+
+// CircleFillPattern fills same pixels as CircleFill with pattern
+func (p *MonoBitmap) CircleFillPattern(p0 image.Point, r int, pattern Pattern) {
+	mask := NewMonoBitmap(p.W, p.H, false)
+	mask.CircleFill(p0, r, true)
+	p.paintMask(&mask, &pattern)
+}
+
+// FillPolygonPattern fills same pixels as FillPolygon with pattern
+func (p *MonoBitmap) FillPolygonPattern(points []image.Point, rule FillRule, pattern Pattern) {
+	mask := NewMonoBitmap(p.W, p.H, false)
+	mask.FillPolygon(points, rule, true)
+	p.paintMask(&mask, &pattern)
+}
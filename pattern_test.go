@@ -0,0 +1,188 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestStockPatterns(t *testing.T) {
+	densities := map[gomonochromebitmap.PatternStyle]int{
+		gomonochromebitmap.PATTERN_GRAY12:       8,
+		gomonochromebitmap.PATTERN_GRAY25:       16,
+		gomonochromebitmap.PATTERN_GRAY50:       32,
+		gomonochromebitmap.PATTERN_GRAY75:       48,
+		gomonochromebitmap.PATTERN_CHECKERBOARD: 32,
+	}
+	for style, n := range densities {
+		bm := gomonochromebitmap.NewMonoBitmap(16, 16, false)
+		bm.FillPattern(image.Rect(0, 0, 15, 15), gomonochromebitmap.StockPattern(style))
+		if countOn(bm) != 4*n {
+			t.Errorf("style %v has %v pixels on", style, countOn(bm))
+		}
+	}
+
+	bm := gomonochromebitmap.NewMonoBitmap(10, 4, false)
+	bm.FillPattern(image.Rect(0, 0, 9, 3), gomonochromebitmap.StockPattern(gomonochromebitmap.PATTERN_HATCH_DIAGONAL))
+	expected := parseBitmap(
+		"...#...#..",
+		"..#...#...",
+		".#...#...#",
+		"#...#...#.",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("diagonal %v", bitmapString(bm))
+	}
+
+	brush := gomonochromebitmap.NewPattern8x8([8]byte{0x80, 0x01})
+	if !brush.At(0, 0) || brush.At(7, 0) || !brush.At(7, 1) || !brush.At(8, 8) || !brush.At(-1, -7) {
+		t.Errorf("brush bit order %v", bitmapString(brush.Tile))
+	}
+}
+
+func TestPatternAlignment(t *testing.T) {
+	//Adjacent fills line up
+	pattern := gomonochromebitmap.StockPattern(gomonochromebitmap.PATTERN_GRAY25)
+	whole := gomonochromebitmap.NewMonoBitmap(40, 20, false)
+	whole.FillPattern(image.Rect(3, 2, 36, 17), pattern)
+	parts := gomonochromebitmap.NewMonoBitmap(40, 20, false)
+	parts.FillPattern(image.Rect(3, 2, 12, 17), pattern)
+	parts.FillPattern(image.Rect(13, 2, 36, 8), pattern)
+	parts.FillPattern(image.Rect(13, 9, 36, 17), pattern)
+	if !equalBitmaps(whole, parts) {
+		t.Errorf("parts %v", bitmapString(parts))
+	}
+
+	//Anchored pattern moves with area
+	moved := gomonochromebitmap.NewMonoBitmap(40, 20, false)
+	moved.FillPattern(image.Rect(5, 5, 38, 20), pattern.Anchored(image.Pt(2, 3)))
+	for y := 0; y < 15; y++ {
+		for x := 0; x < 33; x++ {
+			if whole.GetPix(x+3, y+2) != moved.GetPix(x+5, y+5) {
+				t.Fatalf("anchored differs at %v,%v", x, y)
+			}
+		}
+	}
+}
+
+func TestPatternReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(25))
+	for i := 0; i < 40; i++ {
+		parent := randomBitmap(rnd, 1+rnd.Intn(100), 1+rnd.Intn(20))
+		//Also unaligned views
+		corner := image.Pt(rnd.Intn(parent.W), rnd.Intn(parent.H))
+		bm := parent.SubBitmap(image.Rectangle{corner, parent.Bounds().Max})
+		pattern := gomonochromebitmap.NewPattern(randomBitmap(rnd, 1+rnd.Intn(40), 1+rnd.Intn(5))).Anchored(image.Pt(rnd.Intn(20)-10, rnd.Intn(20)-10))
+		area := image.Rect(rnd.Intn(bm.W+4)-2, rnd.Intn(bm.H+4)-2, rnd.Intn(bm.W+4)-2, rnd.Intn(bm.H+4)-2).Canon()
+
+		before := bm.Clone()
+		bm.ApplyPattern(area, pattern, gomonochromebitmap.ROP_XOR)
+		for y := 0; y < bm.H; y++ {
+			for x := 0; x < bm.W; x++ {
+				expected := before.GetPixNoCheck(x, y)
+				if image.Pt(x, y).In(image.Rect(area.Min.X, area.Min.Y, area.Max.X+1, area.Max.Y+1)) {
+					expected = expected != pattern.At(x+bm.Origin.X, y+bm.Origin.Y)
+				}
+				if bm.GetPixNoCheck(x, y) != expected {
+					t.Fatalf("round %v differs at %v,%v", i, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestPatternShapes(t *testing.T) {
+	pattern := gomonochromebitmap.StockPattern(gomonochromebitmap.PATTERN_HATCH_CROSS)
+	shapes := map[string]func(bm *gomonochromebitmap.MonoBitmap, pattern *gomonochromebitmap.Pattern){
+		"circle": func(bm *gomonochromebitmap.MonoBitmap, pattern *gomonochromebitmap.Pattern) {
+			if pattern == nil {
+				bm.CircleFill(image.Pt(12, 9), 8, true)
+			} else {
+				bm.CircleFillPattern(image.Pt(12, 9), 8, *pattern)
+			}
+		},
+		"polygon": func(bm *gomonochromebitmap.MonoBitmap, pattern *gomonochromebitmap.Pattern) {
+			if pattern == nil {
+				bm.FillPolygon(arrow, gomonochromebitmap.FILL_NONZERO, true)
+			} else {
+				bm.FillPolygonPattern(arrow, gomonochromebitmap.FILL_NONZERO, *pattern)
+			}
+		},
+	}
+	for name, draw := range shapes {
+		mask := gomonochromebitmap.NewMonoBitmap(25, 20, false)
+		draw(&mask, nil)
+		bm := gomonochromebitmap.NewMonoBitmap(25, 20, true)
+		draw(&bm, &pattern)
+		for y := 0; y < bm.H; y++ {
+			for x := 0; x < bm.W; x++ {
+				expected := !mask.GetPixNoCheck(x, y) || pattern.At(x, y)
+				if bm.GetPixNoCheck(x, y) != expected {
+					t.Fatalf("%v differs at %v,%v %v", name, x, y, bitmapString(bm))
+				}
+			}
+		}
+	}
+
+	//Flood fill follows anchor
+	bm := gomonochromebitmap.NewMonoBitmap(16, 8, false)
+	anchored := pattern.Anchored(image.Pt(1, 1))
+	bm.FloodFillPattern(image.Pt(0, 0), anchored, gomonochromebitmap.CONNECT_4)
+	expected := gomonochromebitmap.NewMonoBitmap(16, 8, false)
+	expected.FillPattern(image.Rect(0, 0, 15, 7), anchored)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("flood fill %v", bitmapString(bm))
+	}
+}
+
+func TestPatternGrayOut(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(8, 4, false)
+	bm.Fill(image.Rect(1, 0, 6, 3), true)
+	bm.ApplyPattern(image.Rect(0, 0, 7, 3), gomonochromebitmap.StockPattern(gomonochromebitmap.PATTERN_GRAY50), gomonochromebitmap.ROP_ANDNOT)
+	expected := parseBitmap(
+		".#.#.#..",
+		"..#.#.#.",
+		".#.#.#..",
+		"..#.#.#.",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("grayed %v", bitmapString(bm))
+	}
+}
+
+func TestPatternViews(t *testing.T) {
+	//Sibling views line up like one fill on root
+	gray := gomonochromebitmap.StockPattern(gomonochromebitmap.PATTERN_GRAY50)
+	root := gomonochromebitmap.NewMonoBitmap(12, 3, false)
+	left := root.SubBitmap(image.Rect(0, 0, 5, 3))
+	right := root.SubBitmap(image.Rect(5, 0, 12, 3))
+	left.FillPattern(image.Rect(0, 0, 4, 2), gray)
+	right.FillPattern(image.Rect(0, 0, 6, 2), gray)
+	expected := gomonochromebitmap.NewMonoBitmap(12, 3, false)
+	expected.FillPattern(image.Rect(0, 0, 11, 2), gray)
+	if !equalBitmaps(expected, root) {
+		t.Errorf("views %v", bitmapString(root))
+	}
+
+	//Graying out widget drawn on view
+	root = gomonochromebitmap.NewMonoBitmap(12, 3, true)
+	widget := root.SubBitmap(image.Rect(3, 1, 10, 3))
+	widget.ApplyPattern(image.Rect(0, 0, 6, 1), gray, gomonochromebitmap.ROP_AND)
+	expected = gomonochromebitmap.NewMonoBitmap(12, 3, true)
+	expected.ApplyPattern(image.Rect(3, 1, 9, 2), gray, gomonochromebitmap.ROP_AND)
+	if !equalBitmaps(expected, root) {
+		t.Errorf("grayed widget %v", bitmapString(root))
+	}
+
+	//Flood fill on view
+	root = gomonochromebitmap.NewMonoBitmap(12, 3, false)
+	view := root.SubBitmap(image.Rect(3, 0, 12, 3))
+	view.FloodFillPattern(image.Pt(0, 0), gray, gomonochromebitmap.CONNECT_4)
+	expected = gomonochromebitmap.NewMonoBitmap(12, 3, false)
+	expected.FillPattern(image.Rect(3, 0, 11, 2), gray)
+	if !equalBitmaps(expected, root) {
+		t.Errorf("flood fill on view %v", bitmapString(root))
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Netpbm bitmap (PBM) reading and writing
+
+P1 is ASCII and P4 is binary version. In PBM 1 is black, so black pixel is off
+pixel like MonoColor(false) is. Formats are registered to image package so
+image.Decode can read PBM files.
+*/
+package gomonochromebitmap
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"strconv"
+)
+
+func init() {
+	image.RegisterFormat("pbm", "P1", decodePBMImage, DecodePBMConfig)
+	image.RegisterFormat("pbm", "P4", decodePBMImage, DecodePBMConfig)
+}
+
+const (
+	pbmMaxLineLength = 70
+)
+
+// pbmReader reads header tokens and pixel data
+type pbmReader struct {
+	r *bufio.Reader
+}
+
+// skipSpaceAndComments skips whitespace and comments starting with # until end of line
+func (p *pbmReader) skipSpaceAndComments() error {
+	for {
+		c, err := p.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch c {
+		case ' ', '\t', '\n', '\r', '\v', '\f':
+			continue
+		case '#':
+			if _, errLine := p.r.ReadString('\n'); errLine != nil {
+				return errLine
+			}
+			continue
+		}
+		return p.r.UnreadByte()
+	}
+}
+
+// readInt reads positive decimal number from header
+func (p *pbmReader) readInt() (int, error) {
+	if err := p.skipSpaceAndComments(); err != nil {
+		return 0, err
+	}
+	digits := []byte{}
+	for {
+		c, err := p.r.ReadByte()
+		if err == io.EOF && 0 < len(digits) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if c < '0' || '9' < c {
+			if errUnread := p.r.UnreadByte(); errUnread != nil {
+				return 0, errUnread
+			}
+			break
+		}
+		digits = append(digits, c)
+	}
+	if len(digits) == 0 {
+		return 0, fmt.Errorf("number expected on PBM header")
+	}
+	return strconv.Atoi(string(digits))
+}
+
+// readHeader reads magic number and dimensions. Returns true if format is binary (P4)
+func (p *pbmReader) readHeader() (bool, int, int, error) {
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(p.r, magic); err != nil {
+		return false, 0, 0, err
+	}
+	var binary bool
+	switch string(magic) {
+	case "P1":
+		binary = false
+	case "P4":
+		binary = true
+	default:
+		return false, 0, 0, fmt.Errorf("not PBM file, magic is %q", magic)
+	}
+	w, errW := p.readInt()
+	if errW != nil {
+		return false, 0, 0, errW
+	}
+	h, errH := p.readInt()
+	if errH != nil {
+		return false, 0, 0, errH
+	}
+	if w <= 0 || h <= 0 {
+		return false, 0, 0, fmt.Errorf("invalid PBM dimensions %vx%v", w, h)
+	}
+	if errSize := checkDecodeSize(w, h); errSize != nil {
+		return false, 0, 0, fmt.Errorf("PBM header: %w", errSize)
+	}
+	return binary, w, h, nil
+}
+
+// DecodePBMConfig returns dimensions of PBM image without reading pixels
+func DecodePBMConfig(r io.Reader) (image.Config, error) {
+	reader := pbmReader{r: bufio.NewReader(r)}
+	_, w, h, err := reader.readHeader()
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: MonoModel, Width: w, Height: h}, nil
+}
+
+func decodePBMImage(r io.Reader) (image.Image, error) {
+	bm, err := DecodePBM(r)
+	if err != nil {
+		return nil, err
+	}
+	return &bm, nil
+}
+
+// DecodePBM reads P1 or P4 bitmap. PBM black pixels (1) are off
+func DecodePBM(r io.Reader) (MonoBitmap, error) {
+	reader := pbmReader{r: bufio.NewReader(r)}
+	binary, w, h, errHeader := reader.readHeader()
+	if errHeader != nil {
+		return MonoBitmap{}, errHeader
+	}
+	if binary {
+		//Exactly one whitespace character before data
+		if _, err := reader.r.ReadByte(); err != nil {
+			return MonoBitmap{}, err
+		}
+		//Read data before allocating bitmap, buffer grows only as much as there is data
+		rowBytes := (w + 7) / 8
+		data, errData := io.ReadAll(io.LimitReader(reader.r, int64(h*rowBytes)))
+		if errData != nil {
+			return MonoBitmap{}, errData
+		}
+		if len(data) < h*rowBytes {
+			return MonoBitmap{}, fmt.Errorf("PBM data ended on row %v: %w", len(data)/rowBytes, io.ErrUnexpectedEOF)
+		}
+		result := NewMonoBitmap(w, h, false)
+		for y := 0; y < h; y++ {
+			row := data[y*rowBytes : (y+1)*rowBytes]
+			for x := 0; x < w; x++ {
+				result.SetPixNoCheck(x, y, row[x/8]&(0x80>>uint(x%8)) == 0)
+			}
+		}
+		return result, nil
+	}
+
+	//Words are appended as pixels arrive, so short file does not allocate whole bitmap
+	var pix []uint32
+	for y := 0; y < h; y++ {
+		var word uint32
+		for x := 0; x < w; x++ {
+			if err := reader.skipSpaceAndComments(); err != nil {
+				return MonoBitmap{}, fmt.Errorf("PBM data ended at %v,%v: %w", x, y, err)
+			}
+			c, err := reader.r.ReadByte()
+			if err != nil {
+				return MonoBitmap{}, err
+			}
+			switch c {
+			case '0':
+				word |= 1 << uint32(x&31)
+			case '1':
+			default:
+				return MonoBitmap{}, fmt.Errorf("invalid character %q on PBM data at %v,%v", c, x, y)
+			}
+			if x&31 == 31 || x == w-1 {
+				pix = append(pix, word)
+				word = 0
+			}
+		}
+	}
+	return MonoBitmap{Pix: pix, W: w, H: h, Stride: wordsPerRow(w)}, nil
+}
+
+// EncodePBM writes bitmap as binary P4 or ASCII P1 file. Off pixels are written as black (1)
+func EncodePBM(w io.Writer, bm *MonoBitmap, binary bool) error {
+	out := bufio.NewWriter(w)
+	magic := "P1"
+	if binary {
+		magic = "P4"
+	}
+	if _, err := fmt.Fprintf(out, "%s\n%v %v\n", magic, bm.W, bm.H); err != nil {
+		return err
+	}
+
+	if binary {
+		row := make([]byte, (bm.W+7)/8)
+		for y := 0; y < bm.H; y++ {
+			clear(row)
+			for x := 0; x < bm.W; x++ {
+				if !bm.GetPixNoCheck(x, y) {
+					row[x/8] |= 0x80 >> uint(x%8)
+				}
+			}
+			if _, err := out.Write(row); err != nil {
+				return err
+			}
+		}
+		return out.Flush()
+	}
+
+	for y := 0; y < bm.H; y++ {
+		for x := 0; x < bm.W; x++ {
+			c := byte('1')
+			if bm.GetPixNoCheck(x, y) {
+				c = '0'
+			}
+			if err := out.WriteByte(c); err != nil {
+				return err
+			}
+			if (x+1)%pbmMaxLineLength == 0 && x+1 < bm.W {
+				if err := out.WriteByte('\n'); err != nil {
+					return err
+				}
+			}
+		}
+		if err := out.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
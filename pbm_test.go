@@ -0,0 +1,143 @@
+package gomonochromebitmap_test
+
+import (
+	"bytes"
+	"image"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func equalBitmaps(a gomonochromebitmap.MonoBitmap, b gomonochromebitmap.MonoBitmap) bool {
+	if a.W != b.W || a.H != b.H {
+		return false
+	}
+	for y := 0; y < a.H; y++ {
+		for x := 0; x < a.W; x++ {
+			if a.GetPixNoCheck(x, y) != b.GetPixNoCheck(x, y) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestPBMRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(7))
+	for _, binary := range []bool{false, true} {
+		for _, w := range []int{1, 7, 8, 9, 70, 71, 100} {
+			bm := randomBitmap(rnd, w, 5)
+			var buf bytes.Buffer
+			if err := gomonochromebitmap.EncodePBM(&buf, &bm, binary); err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := gomonochromebitmap.DecodePBM(&buf)
+			if err != nil {
+				t.Fatalf("binary=%v w=%v: %v", binary, w, err)
+			}
+			if !equalBitmaps(bm, decoded) {
+				t.Errorf("binary=%v w=%v round trip failed", binary, w)
+			}
+		}
+	}
+}
+
+func TestPBMDecodeASCII(t *testing.T) {
+	// Example from netpbm documentation, with comments and no separators
+	data := "P1\n# feep\n6 # width\n3\n0 1 0 0 1 0\n011\n# comment inside data\n110\n0 0 0 0 0 0\n"
+	bm, err := gomonochromebitmap.DecodePBM(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"#.##.#", "#....#", "######"} //# is on (white)
+	for y, row := range expected {
+		for x, c := range row {
+			if bm.GetPix(x, y) != (c == '#') {
+				t.Errorf("pixel %v,%v invalid", x, y)
+			}
+		}
+	}
+
+	if _, errShort := gomonochromebitmap.DecodePBM(strings.NewReader("P1 2 2 0 1 1")); errShort == nil {
+		t.Errorf("truncated data must fail")
+	}
+	if _, errMagic := gomonochromebitmap.DecodePBM(strings.NewReader("P2 2 2 0 1 1 1")); errMagic == nil {
+		t.Errorf("graymap must fail")
+	}
+}
+
+func TestPBMImageDecode(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(13, 4, false)
+	bm.Line(image.Point{X: 0, Y: 0}, image.Point{X: 12, Y: 3}, true)
+	var buf bytes.Buffer
+	if err := gomonochromebitmap.EncodePBM(&buf, &bm, true); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, format, errConfig := image.DecodeConfig(bytes.NewReader(buf.Bytes()))
+	if errConfig != nil || format != "pbm" || cfg.Width != 13 || cfg.Height != 4 {
+		t.Fatalf("DecodeConfig failed %v %v %#v", errConfig, format, cfg)
+	}
+	img, _, errDecode := image.Decode(&buf)
+	if errDecode != nil {
+		t.Fatal(errDecode)
+	}
+	decoded, ok := img.(*gomonochromebitmap.MonoBitmap)
+	if !ok {
+		t.Fatalf("decoded image type is %T", img)
+	}
+	if !equalBitmaps(bm, *decoded) {
+		t.Errorf("image.Decode result differs")
+	}
+}
+
+func TestPBMHostileHeader(t *testing.T) {
+	cases := []string{
+		"P4\n4000000000 4000000000\n ",
+		"P1\n4000000000 4000000000\n0",
+		"P4\n60000 60000\n\x00\x00\x00",
+		"P4\n8 2\n\x00",
+	}
+	for _, c := range cases {
+		if _, err := gomonochromebitmap.DecodePBM(strings.NewReader(c)); err == nil {
+			t.Errorf("%q: no error", c)
+		}
+		if _, _, err := image.Decode(strings.NewReader(c)); err == nil {
+			t.Errorf("%q: image.Decode no error", c)
+		}
+	}
+
+	//Short files allocate only for data they have
+	for _, c := range []string{"P1 32768 32768 0 1", "P1 1073741824 1 1 0", "P4 32768 32768 \x00"} {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		gomonochromebitmap.DecodePBM(strings.NewReader(c))
+		runtime.ReadMemStats(&after)
+		if 1<<20 < after.TotalAlloc-before.TotalAlloc {
+			t.Errorf("%q: allocated %v bytes", c, after.TotalAlloc-before.TotalAlloc)
+		}
+	}
+}
+
+func FuzzPBMDecode(f *testing.F) {
+	f.Add([]byte("P1 2 2 0 1 1 0"))
+	f.Add([]byte("P4\n9 2\n\x00\x80\xff\x00"))
+	f.Add([]byte("P4\n4000000000 4000000000\n "))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		bm, err := gomonochromebitmap.DecodePBM(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		var buf bytes.Buffer
+		if errEncode := gomonochromebitmap.EncodePBM(&buf, &bm, true); errEncode != nil {
+			t.Fatal(errEncode)
+		}
+		again, errAgain := gomonochromebitmap.DecodePBM(&buf)
+		if errAgain != nil || !equalBitmaps(bm, again) {
+			t.Errorf("re-encoding failed %v", errAgain)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+/*
+Polygons
+
+Vertices are pixel coordinates, same way as on Line. Outlines are drawn with
+Line, so they are clipped with ClipLine. Fill is done by scanlines through
+pixel centers, then outline is drawn on top so filled shape covers same pixels
+as its outline, like Fill covers Rectangle and CircleFill covers Circle.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// FillRule tells which areas of self-intersecting polygon are inside
+type FillRule byte
+
+const (
+	FILL_EVEN_ODD FillRule = 0 // Inside when ray from point crosses odd number of edges
+	FILL_NONZERO  FillRule = 1 // Inside when edges wind around point, overlapping parts stay filled
+)
+
+// Polyline draws lines between consecutive points
+func (p *MonoBitmap) Polyline(points []image.Point, value bool) {
+	if len(points) == 1 {
+		p.SetPix(points[0].X, points[0].Y, value)
+	}
+	for i := 1; i < len(points); i++ {
+		p.Line(points[i-1], points[i], value)
+	}
+}
+
+// Polygon draws closed outline, last point is connected to first
+func (p *MonoBitmap) Polygon(points []image.Point, value bool) {
+	p.Polyline(points, value)
+	if 2 < len(points) {
+		p.Line(points[len(points)-1], points[0], value)
+	}
+}
+
+// polygonCrossing is edge crossing on scanline
+type polygonCrossing struct {
+	x       float64
+	winding int
+}
+
+// pointF is vertex with subpixel accuracy
+type pointF struct {
+	x, y float64
+}
+
+// fillPolygonF fills scanlines through pixel centers inside polygon, outline is not drawn
+func (p *MonoBitmap) fillPolygonF(points []pointF, rule FillRule, value bool) {
+	if len(points) == 0 {
+		return
+	}
+	fy0, fy1 := points[0].y, points[0].y
+	for _, v := range points {
+		fy0, fy1 = math.Min(fy0, v.y), math.Max(fy1, v.y)
+	}
+	y0, y1 := max(int(math.Ceil(fy0)), 0), min(int(math.Floor(fy1)), p.H-1)
+
+	var crossings []polygonCrossing
+	for y := y0; y <= y1; y++ {
+		fy := float64(y)
+		crossings = crossings[:0]
+		for i, a := range points {
+			b := points[(i+1)%len(points)]
+			winding := 1
+			if b.y < a.y {
+				a, b = b, a
+				winding = -1
+			}
+			if fy < a.y || b.y <= fy { //Edge covers rows a.y...b.y-1, so vertices are not counted twice
+				continue
+			}
+			x := a.x + (fy-a.y)*(b.x-a.x)/(b.y-a.y)
+			crossings = append(crossings, polygonCrossing{x: x, winding: winding})
+		}
+		sort.Slice(crossings, func(i, j int) bool { return crossings[i].x < crossings[j].x })
+
+		winding := 0
+		for i := 0; i+1 < len(crossings); i++ {
+			if rule == FILL_NONZERO {
+				winding += crossings[i].winding
+			} else {
+				winding ^= 1
+			}
+			if winding != 0 {
+				p.Hline(int(math.Ceil(crossings[i].x)), int(math.Floor(crossings[i+1].x)), y, value)
+			}
+		}
+	}
+}
+
+// FillPolygon fills polygon and its outline
+func (p *MonoBitmap) FillPolygon(points []image.Point, rule FillRule, value bool) {
+	vertices := make([]pointF, len(points))
+	for i, v := range points {
+		vertices[i] = pointF{float64(v.X), float64(v.Y)}
+	}
+	p.fillPolygonF(vertices, rule, value)
+	p.Polygon(points, value)
+}
+
+// Triangle draws outline of triangle
+func (p *MonoBitmap) Triangle(a image.Point, b image.Point, c image.Point, value bool) {
+	p.Polygon([]image.Point{a, b, c}, value)
+}
+
+// FillTriangle fills triangle and its outline
+func (p *MonoBitmap) FillTriangle(a image.Point, b image.Point, c image.Point, value bool) {
+	p.FillPolygon([]image.Point{a, b, c}, FILL_EVEN_ODD, value)
+}
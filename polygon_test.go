@@ -0,0 +1,182 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestPolyline(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(12, 6, false)
+	bm.Polyline([]image.Point{{0, 5}, {3, 1}, {6, 4}, {11, 0}}, true)
+	expected := parseBitmap(
+		"...........#",
+		"...#......#.",
+		"..#.#...##..",
+		".#...#.#....",
+		".#....#.....",
+		"#...........",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("got %v", bitmapString(bm))
+	}
+}
+
+var arrow = []image.Point{{0, 3}, {6, 3}, {6, 0}, {11, 5}, {6, 10}, {6, 7}, {0, 7}}
+
+func TestPolygon(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(12, 11, false)
+	bm.Polygon(arrow, true)
+	expected := parseBitmap(
+		"......#.....",
+		"......##....",
+		"......#.#...",
+		"#######..#..",
+		"#.........#.",
+		"#..........#",
+		"#.........#.",
+		"#######..#..",
+		"......#.#...",
+		"......##....",
+		"......#.....",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("outline %v", bitmapString(bm))
+	}
+
+	bm.FillPolygon(arrow, gomonochromebitmap.FILL_EVEN_ODD, true)
+	expected = parseBitmap(
+		"......#.....",
+		"......##....",
+		"......###...",
+		"##########..",
+		"###########.",
+		"############",
+		"###########.",
+		"##########..",
+		"......###...",
+		"......##....",
+		"......#.....",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("fill %v", bitmapString(bm))
+	}
+}
+
+func TestFillRules(t *testing.T) {
+	star := []image.Point{{8, 0}, {13, 15}, {0, 5}, {16, 5}, {3, 15}}
+	evenOdd := gomonochromebitmap.NewMonoBitmap(17, 16, false)
+	evenOdd.FillPolygon(star, gomonochromebitmap.FILL_EVEN_ODD, true)
+	expected := parseBitmap(
+		"........#........",
+		"........#........",
+		".......###.......",
+		".......###.......",
+		".......###.......",
+		"#################",
+		".######...######.",
+		"..#####...#####..",
+		"....##.....##....",
+		".....#.....#.....",
+		".....###.###.....",
+		"....#########....",
+		"....####.####....",
+		"....###...###....",
+		"...##.......##...",
+		"...#.........#...",
+	)
+	if !equalBitmaps(expected, evenOdd) {
+		t.Errorf("even-odd %v", bitmapString(evenOdd))
+	}
+
+	nonZero := gomonochromebitmap.NewMonoBitmap(17, 16, false)
+	nonZero.FillPolygon(star, gomonochromebitmap.FILL_NONZERO, true)
+	expected = parseBitmap(
+		"........#........",
+		"........#........",
+		".......###.......",
+		".......###.......",
+		".......###.......",
+		"#################",
+		".###############.",
+		"..#############..",
+		"....#########....",
+		".....#######.....",
+		".....#######.....",
+		"....#########....",
+		"....####.####....",
+		"....###...###....",
+		"...##.......##...",
+		"...#.........#...",
+	)
+	if !equalBitmaps(expected, nonZero) {
+		t.Errorf("non-zero %v", bitmapString(nonZero))
+	}
+}
+
+func TestTriangle(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(12, 8, false)
+	bm.Triangle(image.Pt(1, 1), image.Pt(10, 3), image.Pt(4, 7), true)
+	expected := parseBitmap(
+		"............",
+		".###........",
+		"..#.####....",
+		"..#.....###.",
+		"...#....##..",
+		"...#...#....",
+		"....###.....",
+		"....#.......",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("outline %v", bitmapString(bm))
+	}
+
+	bm.FillTriangle(image.Pt(1, 1), image.Pt(10, 3), image.Pt(4, 7), true)
+	expected = parseBitmap(
+		"............",
+		".###........",
+		"..######....",
+		"..#########.",
+		"...#######..",
+		"...#####....",
+		"....###.....",
+		"....#.......",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("fill %v", bitmapString(bm))
+	}
+
+	//Erasing with same triangle clears all
+	bm.FillTriangle(image.Pt(4, 7), image.Pt(10, 3), image.Pt(1, 1), false)
+	if countOn(bm) != 0 {
+		t.Errorf("erase %v", bitmapString(bm))
+	}
+}
+
+func TestPolygonClipping(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(10, 8, false)
+	bm.FillTriangle(image.Pt(-6, -4), image.Pt(14, 3), image.Pt(2, 12), true)
+	expected := parseBitmap(
+		"########..",
+		"##########",
+		"##########",
+		"##########",
+		"##########",
+		"##########",
+		"##########",
+		"#########.",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("clipped fill %v", bitmapString(bm))
+	}
+
+	//Shapes completely outside do nothing
+	bm = gomonochromebitmap.NewMonoBitmap(10, 8, false)
+	bm.Polygon([]image.Point{{-10, -10}, {-2, -10}, {-5, -3}}, true)
+	bm.FillPolygon([]image.Point{{20, 0}, {30, 0}, {25, 7}}, gomonochromebitmap.FILL_NONZERO, true)
+	bm.Polyline([]image.Point{{0, 100}, {10, 100}}, true)
+	if countOn(bm) != 0 {
+		t.Errorf("drew outside shapes %v", bitmapString(bm))
+	}
+}
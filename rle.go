@@ -0,0 +1,145 @@
+/*
+Run length coding, version 2
+
+RLEencode and RLEdecode (version 1) have no header and use byte sized runs.
+Version 2 is self describing container
+
+- magic "MRLE"
+- version byte, 2
+- width and height as unsigned varints
+- flags byte, bit 0 is activeFirst
+- runs as unsigned varints
+
+Runs go thru pixels row by row, first run has value activeFirst and runs
+alternate after that. Run can be zero, so first run is zero when first pixel
+is not activeFirst.
+*/
+package gomonochromebitmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	rleMagic   = "MRLE"
+	rleVersion = 2
+)
+
+// EncodeRLE writes bitmap in version 2 run length format
+func EncodeRLE(w io.Writer, bm *MonoBitmap, activeFirst bool) error {
+	out := bufio.NewWriter(w)
+	out.WriteString(rleMagic)
+	out.WriteByte(rleVersion)
+	buf := make([]byte, 0, binary.MaxVarintLen64)
+	out.Write(binary.AppendUvarint(buf, uint64(bm.W)))
+	out.Write(binary.AppendUvarint(buf, uint64(bm.H)))
+	flags := byte(0)
+	if activeFirst {
+		flags |= 1
+	}
+	out.WriteByte(flags)
+
+	if 0 < bm.W*bm.H {
+		activeNow := activeFirst
+		run := uint64(0)
+		for y := 0; y < bm.H; y++ {
+			for x := 0; x < bm.W; x++ {
+				if bm.GetPixNoCheck(x, y) != activeNow {
+					out.Write(binary.AppendUvarint(buf, run))
+					activeNow = !activeNow
+					run = 0
+				}
+				run++
+			}
+		}
+		out.Write(binary.AppendUvarint(buf, run))
+	}
+	return out.Flush()
+}
+
+// DecodeRLE reads version 2 run length coded bitmap. Returns also activeFirst flag of header
+func DecodeRLE(r io.Reader) (MonoBitmap, bool, error) {
+	in, ok := r.(io.ByteReader)
+	if !ok {
+		in = bufio.NewReader(r)
+	}
+	var header [len(rleMagic) + 1]byte
+	for i := range header {
+		b, err := in.ReadByte()
+		if err != nil {
+			return MonoBitmap{}, false, fmt.Errorf("RLE header: %w", unexpectedEOF(err))
+		}
+		header[i] = b
+	}
+	if string(header[:len(rleMagic)]) != rleMagic {
+		return MonoBitmap{}, false, fmt.Errorf("not RLE data")
+	}
+	if header[len(rleMagic)] != rleVersion {
+		return MonoBitmap{}, false, fmt.Errorf("unsupported RLE version %v", header[len(rleMagic)])
+	}
+	w, errW := binary.ReadUvarint(in)
+	if errW != nil {
+		return MonoBitmap{}, false, fmt.Errorf("RLE width: %w", unexpectedEOF(errW))
+	}
+	h, errH := binary.ReadUvarint(in)
+	if errH != nil {
+		return MonoBitmap{}, false, fmt.Errorf("RLE height: %w", unexpectedEOF(errH))
+	}
+	if errSize := checkDecodeSize(int(min(w, maxDecodePixels+1)), int(min(h, maxDecodePixels+1))); errSize != nil { //Clamped so that int conversion can not wrap
+		return MonoBitmap{}, false, fmt.Errorf("RLE header: %w", errSize)
+	}
+	flags, errFlags := in.ReadByte()
+	if errFlags != nil {
+		return MonoBitmap{}, false, fmt.Errorf("RLE flags: %w", unexpectedEOF(errFlags))
+	}
+	if flags&^1 != 0 {
+		return MonoBitmap{}, false, fmt.Errorf("unknown RLE flags %x", flags)
+	}
+	activeFirst := flags&1 != 0
+
+	result := NewMonoBitmap(int(w), int(h), false)
+	activeNow := activeFirst
+	pos := uint64(0)
+	total := w * h
+	for pos < total {
+		run, err := binary.ReadUvarint(in)
+		if err != nil {
+			return MonoBitmap{}, false, fmt.Errorf("RLE run at pixel %v: %w", pos, unexpectedEOF(err))
+		}
+		if total-pos < run {
+			return MonoBitmap{}, false, fmt.Errorf("RLE run %v at pixel %v goes over bitmap end", run, pos)
+		}
+		if activeNow {
+			for i := pos; i < pos+run; i++ {
+				result.SetPixNoCheck(int(i%w), int(i/w), true)
+			}
+		}
+		pos += run
+		activeNow = !activeNow
+	}
+	return result, activeFirst, nil
+}
+
+// RLEencodeV2 returns bitmap in version 2 run length format
+func (p *MonoBitmap) RLEencodeV2(activeFirst bool) []byte {
+	var buf bytes.Buffer
+	EncodeRLE(&buf, p, activeFirst)
+	return buf.Bytes()
+}
+
+// RLEdecodeV2 decodes version 2 run length data. Data is not modified
+func RLEdecodeV2(data []byte) (MonoBitmap, error) {
+	result, _, err := DecodeRLE(bytes.NewReader(data))
+	return result, err
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+package gomonochromebitmap_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+	"testing/iotest"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestRLEv1RoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(15))
+	for _, avgRun := range []int{1, 10, 1000} {
+		bm := runBitmap(rnd, 300, 4, avgRun)
+		for _, activeFirst := range []bool{false, true} {
+			data := bm.RLEencode(activeFirst)
+			original := append([]byte{}, data...)
+			decoded := gomonochromebitmap.NewMonoBitmap(bm.W, bm.H, false)
+			if err := decoded.RLEdecode(activeFirst, data); err != nil {
+				t.Fatal(err)
+			}
+			if !equalBitmaps(bm, decoded) {
+				t.Errorf("run=%v activeFirst=%v round trip failed", avgRun, activeFirst)
+			}
+			if !bytes.Equal(data, original) {
+				t.Errorf("RLEdecode modified data")
+			}
+		}
+	}
+}
+
+func TestRLEv1Invalid(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(10, 10, false)
+	if err := bm.RLEdecode(true, nil); err == nil {
+		t.Errorf("no error on empty data")
+	}
+	if err := bm.RLEdecode(true, []byte{50, 0, 0}); err == nil {
+		t.Errorf("no error on too short data")
+	}
+}
+
+func TestRLERoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(16))
+	for _, size := range [][2]int{{0, 0}, {5, 0}, {1, 1}, {33, 7}, {640, 3}} {
+		for _, avgRun := range []int{1, 10, 100000} {
+			bm := runBitmap(rnd, size[0], size[1], avgRun)
+			for _, activeFirst := range []bool{false, true} {
+				data := bm.RLEencodeV2(activeFirst)
+				//Stream thru reader that is not io.ByteReader
+				decoded, decodedActive, err := gomonochromebitmap.DecodeRLE(iotest.OneByteReader(bytes.NewReader(data)))
+				if err != nil {
+					t.Fatalf("%v run=%v: %v", size, avgRun, err)
+				}
+				if !equalBitmaps(bm, decoded) || decodedActive != activeFirst {
+					t.Errorf("%v run=%v activeFirst=%v round trip failed", size, avgRun, activeFirst)
+				}
+			}
+		}
+	}
+}
+
+func TestRLEFormat(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(200, 1, false)
+	bm.SetPix(0, 0, true)
+	//Header, width 200 as varint. Runs: 0 off, 1 on, 199 off
+	expected := []byte{'M', 'R', 'L', 'E', 2, 0xc8, 0x01, 1, 0, 0, 1, 0xc7, 0x01}
+	if data := bm.RLEencodeV2(false); !bytes.Equal(data, expected) {
+		t.Errorf("got %x expected %x", data, expected)
+	}
+}
+
+func TestRLEInvalid(t *testing.T) {
+	valid := gomonochromebitmap.NewMonoBitmap(10, 10, true)
+	data := valid.RLEencodeV2(true)
+	cases := map[string][]byte{
+		"empty":     {},
+		"magic":     []byte("XRLE\x02\x01\x01\x00\x01"),
+		"version":   []byte("MRLE\x03\x01\x01\x00\x01"),
+		"flags":     []byte("MRLE\x02\x01\x01\x80\x01"),
+		"huge":      []byte("MRLE\x02\xff\xff\xff\xff\x0f\xff\xff\xff\xff\x0f\x00"),
+		"overrun":   []byte("MRLE\x02\x02\x02\x00\x05"),
+		"truncated": data[:len(data)-1],
+	}
+	for name, d := range cases {
+		if _, err := gomonochromebitmap.RLEdecodeV2(d); err == nil {
+			t.Errorf("%v: no error", name)
+		}
+	}
+}
+
+func FuzzRLEDecode(f *testing.F) {
+	rnd := rand.New(rand.NewSource(17))
+	for _, avgRun := range []int{1, 8, 100} {
+		bm := runBitmap(rnd, 20, 5, avgRun)
+		f.Add(bm.RLEencodeV2(true))
+	}
+	f.Add([]byte("MRLE\x02\x00\x00\x00"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		bm, err := gomonochromebitmap.RLEdecodeV2(data)
+		if err != nil {
+			return
+		}
+		again, errAgain := gomonochromebitmap.RLEdecodeV2(bm.RLEencodeV2(true))
+		if errAgain != nil || !equalBitmaps(bm, again) {
+			t.Errorf("re-encoding failed %v", errAgain)
+		}
+	})
+}
+
+func FuzzRLEv1Decode(f *testing.F) {
+	f.Add([]byte{}, 3, 3)
+	f.Add([]byte{0, 255, 0, 4}, 20, 13)
+	f.Fuzz(func(t *testing.T, data []byte, w int, h int) {
+		if w < 0 || h < 0 || 100 < w || 100 < h {
+			return
+		}
+		original := append([]byte{}, data...)
+		bm := gomonochromebitmap.NewMonoBitmap(w, h, false)
+		bm.RLEdecode(false, data)
+		if !bytes.Equal(data, original) {
+			t.Errorf("data modified")
+		}
+	})
+}
+
+func FuzzRLERoundTrip(f *testing.F) {
+	f.Add([]byte{0x0f, 0xf0, 0x00}, 5)
+	f.Fuzz(func(t *testing.T, pix []byte, w int) {
+		if w <= 0 || 64 < w {
+			return
+		}
+		h := len(pix) / ((w + 7) / 8)
+		bm, _ := gomonochromebitmap.UnpackBytes(pix, w, h, gomonochromebitmap.PACK_HORIZONTAL, gomonochromebitmap.MSB_FIRST)
+		decoded, err := gomonochromebitmap.RLEdecodeV2(bm.RLEencodeV2(false))
+		if err != nil || !equalBitmaps(bm, decoded) {
+			t.Errorf("round trip failed %v", err)
+		}
+	})
+}
@@ -0,0 +1,465 @@
+/*
+Strokes with pen width, line caps, joins and dash patterns
+
+Pen of width 1 draws same pixels as Line, Polygon and Circle, dashes are
+counted in pixels along drawn path. Wider pens fill polygons around path with
+half of width on both sides, dash lengths are then measured along path.
+
+Dash pattern is list of on and off run lengths, starting with on run. Odd
+length pattern is repeated twice, like on SVG. Phase moves start of pattern
+and continues over corners of polylines, so dashes flow around rectangles.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+	"math"
+)
+
+// LineCap is shape of open stroke ends and dash ends
+type LineCap byte
+
+const (
+	CAP_BUTT   LineCap = 0 // Ends exactly at end point
+	CAP_ROUND  LineCap = 1 // Half circle around end point
+	CAP_SQUARE LineCap = 2 // Extends half of pen width past end point
+)
+
+// LineJoin is shape of corners between wide polyline segments
+type LineJoin byte
+
+const (
+	JOIN_MITER LineJoin = 0 // Sharp corner, bevel when corner is sharper than miter limit
+	JOIN_ROUND LineJoin = 1 // Circle around corner point
+	JOIN_BEVEL LineJoin = 2 // Corner is cut straight
+)
+
+// strokeMiterLimit is max ratio between miter length and pen width, same as SVG default
+const strokeMiterLimit = 4
+
+// dashEndGap pulls dash end back, so on run of n pixels covers n pixels instead of n+1
+const dashEndGap = 1e-6
+
+// Stroke is pen used for drawing outlines
+type Stroke struct {
+	Width     int      // Pen width in pixels, 0 and 1 are both one pixel
+	Cap       LineCap  // Shape of line ends and dash ends
+	Join      LineJoin // Shape of polyline corners
+	Dash      []int    // On and off run lengths in pixels, empty is solid line
+	DashPhase int      // How many pixels of dash pattern are skipped at start
+}
+
+// dashState tracks position on dash pattern while walking along path
+type dashState struct {
+	pattern []int
+	index   int
+	left    float64 // Length left on current run
+	total   float64 // Length of whole pattern
+}
+
+// newDashState starts dash pattern on given phase. Solid line has run that never ends
+func newDashState(pattern []int, phase int) dashState {
+	total := 0
+	for _, v := range pattern {
+		total += max(v, 0)
+	}
+	if total == 0 {
+		return dashState{left: math.Inf(1)}
+	}
+	runs := make([]int, 0, 2*len(pattern))
+	for _, v := range pattern {
+		runs = append(runs, max(v, 0))
+	}
+	if len(runs)%2 == 1 {
+		runs = append(runs, runs...)
+		total *= 2
+	}
+	phase %= total
+	if phase < 0 {
+		phase += total
+	}
+	d := dashState{pattern: runs, left: float64(runs[0]), total: float64(total)}
+	skip := float64(phase)
+	for 0 < skip && d.left <= skip {
+		skip -= d.left
+		d.next()
+	}
+	d.left -= skip
+	return d
+}
+
+// on tells is pen down on current run
+func (d *dashState) on() bool {
+	return d.index%2 == 0
+}
+
+// next moves to next run of pattern
+func (d *dashState) next() {
+	d.index = (d.index + 1) % len(d.pattern)
+	d.left = float64(d.pattern[d.index])
+}
+
+// advance moves n pixels forward, skipping zero length runs. Whole pattern cycles are skipped in one step
+func (d *dashState) advance(n float64) {
+	if d.pattern != nil && d.left < n {
+		n = d.left + math.Mod(n-d.left, d.total) //Cycle from start of next run leaves state unchanged
+	}
+	d.left -= n
+	for d.left <= 0 && d.pattern != nil {
+		carry := d.left
+		d.next()
+		d.left += carry
+	}
+}
+
+// onAt tells is position along path from start of dash state on dash run, used when pixels are not visited in path order
+func (d dashState) onAt(pos float64) bool {
+	if d.pattern == nil {
+		return true
+	}
+	pos = math.Mod(pos, d.total)
+	for d.left <= pos {
+		pos -= d.left
+		d.next()
+	}
+	return d.on()
+}
+
+// linePixels calls fn for each pixel of Bresenham line from p0 to p1, same pixels as Line
+func linePixels(p0 image.Point, p1 image.Point, fn func(x int, y int)) {
+	dx, dy := p1.X-p0.X, p1.Y-p0.Y
+	sx, sy := 1, 1
+	if dx < 0 {
+		dx, sx = -dx, -1
+	}
+	if dy < 0 {
+		dy, sy = -dy, -1
+	}
+	x, y := p0.X, p0.Y
+	err := dx - dy
+	for {
+		fn(x, y)
+		if x == p1.X && y == p1.Y {
+			return
+		}
+		e2 := 2 * err
+		if -dy < e2 {
+			err -= dy
+			x += sx
+		}
+		if e2 < dx {
+			err += dx
+			y += sy
+		}
+	}
+}
+
+// strokeThin draws one pixel wide path, dashes are counted in pixels
+func (p *MonoBitmap) strokeThin(points []image.Point, closed bool, s Stroke, value bool) {
+	dash := newDashState(s.Dash, s.DashPhase)
+	if dash.pattern == nil {
+		if closed {
+			p.Polygon(points, value)
+		} else {
+			p.Polyline(points, value)
+		}
+		return
+	}
+	if len(points) == 1 {
+		p.SetPix(points[0].X, points[0].Y, value)
+		return
+	}
+	if closed && 2 < len(points) {
+		points = append(append([]image.Point{}, points...), points[0])
+	}
+	dash.advance(0) //Zero length runs have no pixels
+
+	//Segments are clipped like on Line, dash skips over clipped pixels
+	bounds := image.Rect(0, 0, p.W-1, p.H-1)
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		k0, k1 := 0, lineSteps(a, b) //Pixel indexes from a to b
+		if 1 < i {
+			k0 = 1 //Corner pixel was drawn by previous segment
+		}
+		if closed && i == len(points)-1 {
+			k1-- //End of closed path is its start
+		}
+		if k1 < k0 {
+			continue
+		}
+		done := 0 //Pixels of segment passed on dash
+		c0, c1 := ClipLine(a, b, bounds)
+		if c0 != nil {
+			k := lineSteps(a, *c0)
+			linePixels(*c0, *c1, func(x int, y int) {
+				if k0 <= k && k <= k1 {
+					dash.advance(float64(k - k0 - done))
+					if dash.on() {
+						p.SetPixNoCheck(x, y, value)
+					}
+					dash.advance(1)
+					done = k - k0 + 1
+				}
+				k++
+			})
+		}
+		dash.advance(float64(k1 - k0 + 1 - done))
+	}
+}
+
+// lineSteps is number of Bresenham steps from a to b
+func lineSteps(a image.Point, b image.Point) int {
+	d := b.Sub(a)
+	return max(d.X, -d.X, d.Y, -d.Y)
+}
+
+// visibleSpan returns part t0...t1 of segment starting from a to unit direction d that is inside bitmap grown by pad. Empty when t1 < t0
+func (p *MonoBitmap) visibleSpan(a pointF, d pointF, length float64, pad float64) (float64, float64) {
+	t0, t1 := 0.0, length
+	clip := func(pos float64, dir float64, lo float64, hi float64) {
+		if dir == 0 {
+			if pos < lo || hi < pos {
+				t1 = -1
+			}
+			return
+		}
+		ta, tb := (lo-pos)/dir, (hi-pos)/dir
+		if tb < ta {
+			ta, tb = tb, ta
+		}
+		t0, t1 = math.Max(t0, ta), math.Min(t1, tb)
+	}
+	clip(a.x, d.x, -pad, float64(p.W-1)+pad)
+	clip(a.y, d.y, -pad, float64(p.H-1)+pad)
+	return t0, t1
+}
+
+// fillDisk fills pixels within radius from center. Rows are half open like on polygon fill
+func (p *MonoBitmap) fillDisk(center pointF, radius float64, value bool) {
+	for y := int(math.Ceil(center.y - radius)); float64(y) < center.y+radius; y++ {
+		dy := float64(y) - center.y
+		half := math.Sqrt(math.Max(0, radius*radius-dy*dy))
+		p.Hline(int(math.Ceil(center.x-half)), int(math.Floor(center.x+half)), y, value)
+	}
+}
+
+// strokeSegment fills pen wide band from a to b, d is unit direction of band
+func (p *MonoBitmap) strokeSegment(a pointF, b pointF, d pointF, hw float64, value bool) {
+	if (b.x-a.x)*d.x+(b.y-a.y)*d.y <= 0 {
+		return
+	}
+	nx, ny := -d.y*hw, d.x*hw
+	p.fillPolygonF([]pointF{{a.x + nx, a.y + ny}, {b.x + nx, b.y + ny}, {b.x - nx, b.y - ny}, {a.x - nx, a.y - ny}}, FILL_NONZERO, value)
+}
+
+// strokeCap draws cap on end point q of stroke going to direction d. Start caps point backwards
+func (p *MonoBitmap) strokeCap(q pointF, d pointF, hw float64, c LineCap, start bool, value bool) {
+	switch c {
+	case CAP_ROUND:
+		p.fillDisk(q, hw, value)
+	case CAP_SQUARE:
+		if start {
+			d = pointF{-d.x, -d.y}
+		}
+		p.strokeSegment(q, pointF{q.x + d.x*hw, q.y + d.y*hw}, d, hw, value)
+	}
+}
+
+// strokeJoin fills outer side of corner v where direction changes from d1 to d2
+func (p *MonoBitmap) strokeJoin(v pointF, d1 pointF, d2 pointF, hw float64, join LineJoin, value bool) {
+	if join == JOIN_ROUND {
+		p.fillDisk(v, hw, value)
+		return
+	}
+	cross := d1.x*d2.y - d1.y*d2.x
+	if math.Abs(cross) < 1e-9 {
+		return //Straight or reversing, nothing to join
+	}
+	side := 1.0
+	if 0 < cross {
+		side = -1 //Turning towards normal, outer side is opposite
+	}
+	n1 := pointF{-d1.y * side, d1.x * side}
+	n2 := pointF{-d2.y * side, d2.x * side}
+	a := pointF{v.x + n1.x*hw, v.y + n1.y*hw}
+	b := pointF{v.x + n2.x*hw, v.y + n2.y*hw}
+
+	m := pointF{n1.x + n2.x, n1.y + n2.y}
+	mLen := math.Hypot(m.x, m.y)
+	if join == JOIN_MITER && 0 < mLen {
+		cosHalf := (m.x*n1.x + m.y*n1.y) / mLen
+		if 1 <= cosHalf*strokeMiterLimit {
+			k := hw / cosHalf / mLen
+			p.fillPolygonF([]pointF{v, a, {v.x + m.x*k, v.y + m.y*k}, b}, FILL_NONZERO, value)
+			return
+		}
+	}
+	p.fillPolygonF([]pointF{v, a, b}, FILL_NONZERO, value)
+}
+
+// strokePath draws polyline or closed polygon with pen
+func (p *MonoBitmap) strokePath(points []image.Point, closed bool, s Stroke, value bool) {
+	if len(points) == 0 {
+		return
+	}
+	if s.Width <= 1 {
+		p.strokeThin(points, closed, s, value)
+		return
+	}
+	hw := float64(s.Width) / 2
+	if closed && 2 < len(points) {
+		points = append(append([]image.Point{}, points...), points[0])
+	}
+
+	//Directions of segments, zero length segments are dropped
+	var vertices, dirs []pointF
+	vertices = append(vertices, pointF{float64(points[0].X), float64(points[0].Y)})
+	for _, v := range points[1:] {
+		prev := vertices[len(vertices)-1]
+		q := pointF{float64(v.X), float64(v.Y)}
+		length := math.Hypot(q.x-prev.x, q.y-prev.y)
+		if length == 0 {
+			continue
+		}
+		vertices = append(vertices, q)
+		dirs = append(dirs, pointF{(q.x - prev.x) / length, (q.y - prev.y) / length})
+	}
+	if len(dirs) == 0 { //Single dot
+		if s.Cap != CAP_BUTT {
+			p.strokeCap(vertices[0], pointF{1, 0}, hw, s.Cap, true, value)
+			p.strokeCap(vertices[0], pointF{1, 0}, hw, s.Cap, false, value)
+		}
+		return
+	}
+
+	dash := newDashState(s.Dash, s.DashPhase)
+	solidLoop := closed && dash.pattern == nil
+	if dash.on() && !solidLoop {
+		p.strokeCap(vertices[0], dirs[0], hw, s.Cap, true, value)
+	}
+	for i, d := range dirs {
+		a, b := vertices[i], vertices[i+1]
+		length := math.Hypot(b.x-a.x, b.y-a.y)
+		if 0 < i && dash.on() {
+			p.strokeJoin(a, dirs[i-1], d, hw, s.Join, value)
+		}
+		//Only part near bitmap is walked, caps further away are not visible
+		t0, t1 := p.visibleSpan(a, d, length, hw+2)
+		if t1 < t0 {
+			dash.advance(length)
+			continue
+		}
+		if 0 < t0 {
+			dash.advance(t0)
+		}
+		t := t0
+		for {
+			rest := t1 - t
+			if rest < dash.left {
+				if dash.on() {
+					p.strokeSegment(pointF{a.x + d.x*t, a.y + d.y*t}, pointF{a.x + d.x*t1, a.y + d.y*t1}, d, hw, value)
+				}
+				dash.left -= rest
+				break
+			}
+			q := pointF{a.x + d.x*(t+dash.left), a.y + d.y*(t+dash.left)}
+			if dash.on() {
+				end := pointF{q.x - d.x*dashEndGap, q.y - d.y*dashEndGap}
+				p.strokeSegment(pointF{a.x + d.x*t, a.y + d.y*t}, end, d, hw, value)
+			}
+			p.strokeCap(q, d, hw, s.Cap, !dash.on(), value)
+			t += dash.left
+			dash.next()
+		}
+		dash.advance(length - t1)
+	}
+	last := dirs[len(dirs)-1]
+	if solidLoop {
+		p.strokeJoin(vertices[0], last, dirs[0], hw, s.Join, value)
+	} else if dash.on() {
+		p.strokeCap(vertices[len(vertices)-1], last, hw, s.Cap, false, value)
+	}
+}
+
+// StrokeLine draws line from p0 to p1 with pen
+func (p *MonoBitmap) StrokeLine(p0 image.Point, p1 image.Point, s Stroke, value bool) {
+	p.strokePath([]image.Point{p0, p1}, false, s, value)
+}
+
+// StrokePolyline draws lines between consecutive points with pen, corners are joined
+func (p *MonoBitmap) StrokePolyline(points []image.Point, s Stroke, value bool) {
+	p.strokePath(points, false, s, value)
+}
+
+// StrokePolygon draws closed outline with pen, last point is connected to first
+func (p *MonoBitmap) StrokePolygon(points []image.Point, s Stroke, value bool) {
+	p.strokePath(points, true, s, value)
+}
+
+// StrokeRectangle draws rectangle outline with pen centered on edges. Area includes Max like on Rectangle
+func (p *MonoBitmap) StrokeRectangle(area image.Rectangle, s Stroke, value bool) {
+	p.strokePath([]image.Point{area.Min, {area.Max.X, area.Min.Y}, area.Max, {area.Min.X, area.Max.Y}}, true, s, value)
+}
+
+// StrokeCircle draws circle outline with pen centered on radius. Dashes start from right and go clockwise, caps are not drawn
+func (p *MonoBitmap) StrokeCircle(center image.Point, r int, s Stroke, value bool) {
+	dash := newDashState(s.Dash, s.DashPhase)
+	if s.Width <= 1 && dash.pattern == nil {
+		p.Circle(center, r, value)
+		return
+	}
+	bounds := p.LocalBounds()
+	plot := func(dx int, dy int) { //Dash is found from angle, so pixels can be visited in any order
+		q := center.Add(image.Pt(dx, dy))
+		if !q.In(bounds) {
+			return
+		}
+		if dash.pattern != nil {
+			a := math.Atan2(float64(dy), float64(dx))
+			if a < 0 {
+				a += 2 * math.Pi
+			}
+			if !dash.onAt(a * float64(r)) {
+				return
+			}
+		}
+		p.SetPixNoCheck(q.X, q.Y, value)
+	}
+
+	if s.Width <= 1 { //Same pixels as Circle
+		x, y, err := r, 0, 0
+		for x >= y {
+			plot(x, y)
+			plot(y, x)
+			plot(-y, x)
+			plot(-x, y)
+			plot(-x, -y)
+			plot(-y, -x)
+			plot(y, -x)
+			plot(x, -y)
+			y += 1
+			err += 1 + 2*y
+			if 2*(err-x)+1 > 0 {
+				x -= 1
+				err += 1 - 2*x
+			}
+		}
+		return
+	}
+
+	//Only rows and columns inside bitmap are walked
+	hw := float64(s.Width) / 2
+	inner, outer := float64(r)-hw, float64(r)+hw
+	reach := int(math.Ceil(outer))
+	area := image.Rectangle{Min: image.Pt(-reach, -reach), Max: image.Pt(reach+1, reach+1)}.Intersect(bounds.Sub(center))
+	for dy := area.Min.Y; dy < area.Max.Y; dy++ {
+		for dx := area.Min.X; dx < area.Max.X; dx++ {
+			d2 := float64(dx*dx + dy*dy)
+			if d2 < inner*inner && 0 <= inner || outer*outer <= d2 {
+				continue
+			}
+			plot(dx, dy)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestStrokeCaps(t *testing.T) {
+	expected := map[gomonochromebitmap.LineCap]gomonochromebitmap.MonoBitmap{
+		gomonochromebitmap.CAP_BUTT: parseBitmap(
+			"................",
+			"....########....",
+			"....########....",
+			"....########....",
+			"....########....",
+			"....########....",
+			"................",
+		),
+		gomonochromebitmap.CAP_ROUND: parseBitmap(
+			"................",
+			"...##########...",
+			"..############..",
+			"..############..",
+			"..############..",
+			"...##########...",
+			"................",
+		),
+		gomonochromebitmap.CAP_SQUARE: parseBitmap(
+			"................",
+			"..############..",
+			"..############..",
+			"..############..",
+			"..############..",
+			"..############..",
+			"................",
+		),
+	}
+	for c, e := range expected {
+		bm := gomonochromebitmap.NewMonoBitmap(16, 7, false)
+		bm.StrokeLine(image.Pt(4, 3), image.Pt(11, 3), gomonochromebitmap.Stroke{Width: 5, Cap: c}, true)
+		if !equalBitmaps(e, bm) {
+			t.Errorf("cap %v %v", c, bitmapString(bm))
+		}
+	}
+}
+
+func TestStrokeJoins(t *testing.T) {
+	expected := map[gomonochromebitmap.LineJoin]gomonochromebitmap.MonoBitmap{
+		gomonochromebitmap.JOIN_MITER: parseBitmap(
+			"..............",
+			"......#.......",
+			".....###......",
+			".....###......",
+			"....#####.....",
+			"....######....",
+			"...#######....",
+			"...###.####...",
+			"..####..###...",
+			"..###...####..",
+			".####....####.",
+			"..##......##..",
+			"..............",
+		),
+		gomonochromebitmap.JOIN_ROUND: parseBitmap(
+			"..............",
+			"..............",
+			".....###......",
+			".....###......",
+			"....#####.....",
+			"....######....",
+			"...#######....",
+			"...###.####...",
+			"..####..###...",
+			"..###...####..",
+			".####....####.",
+			"..##......##..",
+			"..............",
+		),
+		gomonochromebitmap.JOIN_BEVEL: parseBitmap(
+			"..............",
+			"..............",
+			"..............",
+			".....###......",
+			"....#####.....",
+			"....######....",
+			"...#######....",
+			"...###.####...",
+			"..####..###...",
+			"..###...####..",
+			".####....####.",
+			"..##......##..",
+			"..............",
+		),
+	}
+	for j, e := range expected {
+		bm := gomonochromebitmap.NewMonoBitmap(14, 13, false)
+		bm.StrokePolyline([]image.Point{{2, 11}, {6, 3}, {11, 11}}, gomonochromebitmap.Stroke{Width: 3, Join: j}, true)
+		if !equalBitmaps(e, bm) {
+			t.Errorf("join %v %v", j, bitmapString(bm))
+		}
+	}
+
+	bm := gomonochromebitmap.NewMonoBitmap(12, 10, false)
+	bm.StrokeRectangle(image.Rect(2, 2, 9, 7), gomonochromebitmap.Stroke{Width: 3}, true)
+	e := parseBitmap(
+		"............",
+		".##########.",
+		".##########.",
+		".##########.",
+		".###....###.",
+		".###....###.",
+		".##########.",
+		".##########.",
+		".##########.",
+		"............",
+	)
+	if !equalBitmaps(e, bm) {
+		t.Errorf("rectangle %v", bitmapString(bm))
+	}
+}
+
+func TestStrokeDash(t *testing.T) {
+	//Dashes continue around corners
+	bm := gomonochromebitmap.NewMonoBitmap(16, 6, false)
+	bm.StrokeRectangle(image.Rect(0, 0, 15, 5), gomonochromebitmap.Stroke{Dash: []int{3, 2}}, true)
+	expected := parseBitmap(
+		"###..###..###..#",
+		"...............#",
+		"...............#",
+		"#...............",
+		"#...............",
+		"#..###..###..###",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("dashed rectangle %v", bitmapString(bm))
+	}
+
+	bm = gomonochromebitmap.NewMonoBitmap(20, 1, false)
+	bm.StrokeLine(image.Pt(0, 0), image.Pt(19, 0), gomonochromebitmap.Stroke{Dash: []int{1}, DashPhase: 1}, true)
+	if !equalBitmaps(parseBitmap(".#.#.#.#.#.#.#.#.#.#"), bm) {
+		t.Errorf("dotted with phase %v", bitmapString(bm))
+	}
+
+	bm = gomonochromebitmap.NewMonoBitmap(20, 5, false)
+	bm.StrokeLine(image.Pt(0, 2), image.Pt(19, 2), gomonochromebitmap.Stroke{Width: 3, Dash: []int{4, 3}}, true)
+	expected = parseBitmap(
+		"....................",
+		"####...####...####..",
+		"####...####...####..",
+		"####...####...####..",
+		"....................",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("wide dashes %v", bitmapString(bm))
+	}
+
+	//Zero length dashes with round caps are dots
+	bm = gomonochromebitmap.NewMonoBitmap(20, 5, false)
+	bm.StrokeLine(image.Pt(2, 2), image.Pt(17, 2), gomonochromebitmap.Stroke{Width: 3, Cap: gomonochromebitmap.CAP_ROUND, Dash: []int{0, 5}}, true)
+	expected = parseBitmap(
+		"....................",
+		".###..###..###..###.",
+		".###..###..###..###.",
+		".###..###..###..###.",
+		"....................",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("dots %v", bitmapString(bm))
+	}
+
+	//Phase of whole pattern is same as no phase
+	a := gomonochromebitmap.NewMonoBitmap(30, 20, false)
+	a.StrokePolygon(arrow, gomonochromebitmap.Stroke{Width: 2, Dash: []int{5, 2, 1, 2}}, true)
+	b := gomonochromebitmap.NewMonoBitmap(30, 20, false)
+	b.StrokePolygon(arrow, gomonochromebitmap.Stroke{Width: 2, Dash: []int{5, 2, 1, 2}, DashPhase: -30}, true)
+	if !equalBitmaps(a, b) {
+		t.Errorf("phase %v", bitmapString(b))
+	}
+}
+
+func TestStrokeThin(t *testing.T) {
+	//Solid one pixel pen is same as plain drawing functions
+	a := gomonochromebitmap.NewMonoBitmap(12, 11, false)
+	a.StrokePolygon(arrow, gomonochromebitmap.Stroke{}, true)
+	b := gomonochromebitmap.NewMonoBitmap(12, 11, false)
+	b.Polygon(arrow, true)
+	if !equalBitmaps(a, b) {
+		t.Errorf("polygon %v", bitmapString(a))
+	}
+
+	a = gomonochromebitmap.NewMonoBitmap(15, 15, false)
+	a.StrokeCircle(image.Pt(7, 7), 6, gomonochromebitmap.Stroke{Width: 1}, true)
+	b = gomonochromebitmap.NewMonoBitmap(15, 15, false)
+	b.Circle(image.Pt(7, 7), 6, true)
+	if !equalBitmaps(a, b) {
+		t.Errorf("circle %v", bitmapString(a))
+	}
+
+	//Dashed circle is part of circle
+	dashed := gomonochromebitmap.NewMonoBitmap(15, 15, false)
+	dashed.StrokeCircle(image.Pt(7, 7), 6, gomonochromebitmap.Stroke{Dash: []int{3, 3}}, true)
+	dashed.DrawBitmapOp(b, b.Bounds(), image.Point{}, gomonochromebitmap.ROP_ANDNOT)
+	if countOn(dashed) != 0 {
+		t.Errorf("dashed circle outside circle %v", bitmapString(dashed))
+	}
+}
+
+func TestStrokeCircle(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(15, 15, false)
+	bm.StrokeCircle(image.Pt(7, 7), 5, gomonochromebitmap.Stroke{Width: 3}, true)
+	expected := parseBitmap(
+		"...............",
+		".....#####.....",
+		"...#########...",
+		"..###########..",
+		"..####...####..",
+		".####.....####.",
+		".###.......###.",
+		".###.......###.",
+		".###.......###.",
+		".####.....####.",
+		"..####...####..",
+		"..###########..",
+		"...#########...",
+		".....#####.....",
+		"...............",
+	)
+	if !equalBitmaps(expected, bm) {
+		t.Errorf("wide circle %v", bitmapString(bm))
+	}
+}
+
+func TestStrokeClipping(t *testing.T) {
+	//Dashes outside bitmap are skipped without walking them
+	for _, width := range []int{1, 3} {
+		s := gomonochromebitmap.Stroke{Width: width, Dash: []int{2, 2}}
+		start := time.Now()
+		long := gomonochromebitmap.NewMonoBitmap(64, 64, false)
+		long.StrokeLine(image.Pt(-200000000, 5), image.Pt(200000000, 5), s, true)
+		long.StrokePolygon([]image.Point{{-4000000, 20}, {4000000, 20}, {4000000, 40}, {-4000000, 40}}, s, true)
+		if time.Since(start) > time.Second {
+			t.Errorf("width %v took %v", width, time.Since(start))
+		}
+
+		//Clipped result is same as drawing visible part with matching phase
+		clipped := gomonochromebitmap.NewMonoBitmap(64, 64, false)
+		clipped.StrokeLine(image.Pt(-1001, 30), image.Pt(100, 30), s, true)
+		s.DashPhase = 1001
+		direct := gomonochromebitmap.NewMonoBitmap(64, 64, false)
+		direct.StrokeLine(image.Pt(0, 30), image.Pt(100, 30), s, true)
+		if !equalBitmaps(direct, clipped) {
+			t.Errorf("width %v clipped %v", width, bitmapString(clipped))
+		}
+
+		//Large circle walks only pixels near bitmap
+		for _, dash := range [][]int{nil, {5, 3}} {
+			s := gomonochromebitmap.Stroke{Width: width, Dash: dash}
+			start := time.Now()
+			huge := gomonochromebitmap.NewMonoBitmap(128, 64, false)
+			huge.StrokeCircle(image.Pt(64, 3000000), 2999990, s, true)
+			if time.Since(start) > time.Second {
+				t.Errorf("width %v dash %v circle took %v", width, dash, time.Since(start))
+			}
+			if countOn(huge) == 0 {
+				t.Errorf("width %v dash %v circle not drawn", width, dash)
+			}
+
+			whole := gomonochromebitmap.NewMonoBitmap(200, 200, false)
+			whole.StrokeCircle(image.Pt(100, 100), 80, s, true)
+			part := gomonochromebitmap.NewMonoBitmap(64, 64, false)
+			part.StrokeCircle(image.Pt(-20, 70), 80, s, true)
+			view := whole.SubBitmap(image.Rect(120, 30, 184, 94))
+			if !equalBitmaps(view.Clone(), part) {
+				t.Errorf("width %v dash %v clipped circle %v", width, dash, bitmapString(part))
+			}
+		}
+	}
+}
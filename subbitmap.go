@@ -0,0 +1,40 @@
+/*
+Zero-copy views
+
+SubBitmap works like image.RGBA SubImage, but coordinates are translated so
+that corner of view is (0,0). All drawing on view is clipped inside view.
+View of area outside parent is empty and drawing on it does nothing.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+)
+
+// SubBitmap returns view of area r. View shares pixel data with p, drawing on view changes p. Area is clipped inside bitmap
+func (p *MonoBitmap) SubBitmap(r image.Rectangle) MonoBitmap {
+	r = r.Intersect(p.LocalBounds())
+	if r.Empty() {
+		return MonoBitmap{Stride: p.Stride, Origin: p.Origin.Add(r.Min), dirty: p.dirty, view: true}
+	}
+	bit := p.OffsetX + r.Min.X
+	start := r.Min.Y*p.Stride + bit>>5
+	end := (r.Max.Y-1)*p.Stride + wordsPerRow(bit+r.Dx())
+	return MonoBitmap{
+		Pix:     p.Pix[start:end],
+		W:       r.Dx(),
+		H:       r.Dy(),
+		Stride:  p.Stride,
+		OffsetX: bit & 31,
+		Origin:  p.Origin.Add(r.Min),
+		dirty:   p.dirty,
+		view:    true,
+	}
+}
+
+// Clone returns copy of bitmap with own pixel data. Cloning view gives normal bitmap
+func (p *MonoBitmap) Clone() MonoBitmap {
+	result := NewMonoBitmap(p.W, p.H, false)
+	result.DrawBitmapOp(*p, p.LocalBounds(), image.Point{}, ROP_COPY)
+	return result
+}
@@ -0,0 +1,131 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestSubBitmap(t *testing.T) {
+	parent := gomonochromebitmap.NewMonoBitmap(100, 40, false)
+	area := image.Rect(35, 10, 70, 30)
+	view := parent.SubBitmap(area)
+	if view.Bounds() != area || view.LocalBounds() != image.Rect(0, 0, 35, 20) || view.Origin != area.Min {
+		t.Fatalf("invalid view bounds %v local %v origin %v", view.Bounds(), view.LocalBounds(), view.Origin)
+	}
+
+	//Drawing primitives must stay inside view
+	view.Fill(image.Rect(-10, -10, 100, 2), true)
+	view.Line(image.Point{X: -50, Y: 10}, image.Point{X: 200, Y: 10}, true)
+	view.Vline(34, -100, 100, true)
+	view.CircleFill(image.Point{X: 0, Y: 19}, 5, true)
+	view.Print("Hello", gomonochromebitmap.GetFont_8x8(), 8, 1, image.Rect(20, 12, 200, 200), true, true, false, false)
+
+	for y := 0; y < parent.H; y++ {
+		for x := 0; x < parent.W; x++ {
+			if !image.Pt(x, y).In(area) && parent.GetPix(x, y) {
+				t.Fatalf("pixel %v,%v drawn outside of view", x, y)
+			}
+		}
+	}
+	if !parent.GetPix(35, 10) || !parent.GetPix(69, 12) || !parent.GetPix(69, 21) || !parent.GetPix(50, 20) {
+		t.Errorf("view did not draw on parent")
+	}
+
+	//View of view
+	inner := view.SubBitmap(image.Rect(3, 5, 10, 8))
+	if inner.Origin != image.Pt(38, 15) {
+		t.Errorf("invalid origin on nested view %v", inner.Origin)
+	}
+	inner.SetPix(0, 0, true)
+	if !parent.GetPix(38, 15) {
+		t.Errorf("nested view did not draw on parent")
+	}
+
+	clone := view.Clone()
+	for y := 0; y < view.H; y++ {
+		for x := 0; x < view.W; x++ {
+			if clone.GetPix(x, y) != parent.GetPix(x+35, y+10) {
+				t.Fatalf("clone differs at %v,%v", x, y)
+			}
+		}
+	}
+}
+
+func TestSubBitmapDrawBitmap(t *testing.T) {
+	parent := gomonochromebitmap.NewMonoBitmap(64, 16, false)
+	parent.Hline(0, 63, 0, true)
+	left := parent.SubBitmap(image.Rect(0, 0, 30, 16))
+	right := parent.SubBitmap(image.Rect(33, 0, 64, 16))
+
+	//Views of same parent, copy first row of left on row 5 of right
+	right.DrawBitmap(left, image.Rect(0, 0, 30, 1), image.Point{X: 1, Y: 5}, true, true, false)
+	for x := 0; x < 64; x++ {
+		if parent.GetPix(x, 5) != (34 <= x && x < 64) {
+			t.Errorf("pixel %v on row 5 invalid", x)
+		}
+	}
+}
+
+func TestSubBitmapTransforms(t *testing.T) {
+	rnd := rand.New(rand.NewSource(4))
+	ops := map[string]func(bm *gomonochromebitmap.MonoBitmap){
+		"Invert":    func(bm *gomonochromebitmap.MonoBitmap) { bm.Invert(bm.LocalBounds()) },
+		"FlipH":     func(bm *gomonochromebitmap.MonoBitmap) { bm.FlipH() },
+		"FlipV":     func(bm *gomonochromebitmap.MonoBitmap) { bm.FlipV() },
+		"Rotate90":  func(bm *gomonochromebitmap.MonoBitmap) { bm.Rotate90(1) },
+		"Rotate180": func(bm *gomonochromebitmap.MonoBitmap) { bm.Rotate90(2) },
+	}
+	for name, op := range ops {
+		//Square view is changed in place on parent
+		parent := randomBitmap(rnd, 50, 20)
+		area := image.Rect(17, 3, 30, 16)
+		expected := parent.Clone()
+		part := parent.SubBitmap(area)
+		part = part.Clone()
+		op(&part)
+		expected.DrawBitmapOp(part, part.LocalBounds(), area.Min, gomonochromebitmap.ROP_COPY)
+
+		parent.TrackDirty(true)
+		view := parent.SubBitmap(area)
+		op(&view)
+		if !equalBitmaps(expected, parent) {
+			t.Errorf("%v on view %v", name, bitmapString(parent))
+		}
+		if view.Origin != area.Min || parent.DirtyRect() != area {
+			t.Errorf("%v detached view, origin %v dirty %v", name, view.Origin, parent.DirtyRect())
+		}
+
+		//Empty view does nothing
+		before := parent.Clone()
+		empty := parent.SubBitmap(image.Rect(60, 30, 70, 40))
+		op(&empty)
+		empty.Fill(empty.LocalBounds(), true)
+		empty.Line(image.Pt(-5, -5), image.Pt(5, 5), true)
+		if !equalBitmaps(before, parent) || empty.W != 0 || empty.H != 0 {
+			t.Errorf("%v on empty view changed bitmap", name)
+		}
+	}
+
+	//Non-square view can not turn 90 degrees
+	parent := randomBitmap(rnd, 50, 20)
+	before := parent.Clone()
+	view := parent.SubBitmap(image.Rect(5, 5, 30, 10))
+	view.Rotate90(1)
+	if view.W != 25 || view.H != 5 || view.Origin != image.Pt(5, 5) || !equalBitmaps(before, parent) {
+		t.Errorf("non-square view rotated to %vx%v", view.W, view.H)
+	}
+	view.Rotate90(-2)
+	view.Rotate90(2)
+	if !equalBitmaps(before, parent) {
+		t.Errorf("180 degree turn twice changed parent")
+	}
+
+	//Root bitmap changes size
+	parent.Rotate90(1)
+	if parent.W != 20 || parent.H != 50 || parent.GetPix(14, 5) != before.GetPix(5, 5) {
+		t.Errorf("root bitmap not rotated, %vx%v", parent.W, parent.H)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Automatic threshold selection
+
+Otsu picks one global threshold from histogram. Adaptive methods calculate
+threshold for each pixel from its neighbourhood so uneven lighting on scanned
+labels does not matter.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+	"math"
+)
+
+// OtsuThreshold returns threshold that THRESHOLD_OTSU uses on area of image. Pixels brighter than threshold are on
+func OtsuThreshold(img image.Image, area image.Rectangle, luma LumaMode) byte {
+	return otsu(grayLevels(img, area.Intersect(img.Bounds()), luma))
+}
+
+// otsu returns threshold that maximizes between-class variance of gray levels (0-255). Uniform image has only one class, its level is returned so all pixels are off
+func otsu(levels []float32) byte {
+	var histogram [256]int
+	for _, v := range levels {
+		histogram[min(255, max(0, int(v+0.5)))]++
+	}
+	total := len(levels)
+	sum := 0.0
+	for i, n := range histogram {
+		sum += float64(i * n)
+	}
+
+	sumBackground := 0.0
+	weightBackground := 0
+	bestVariance := -1.0
+	best := 0
+	for t := 0; t < 256; t++ {
+		weightBackground += histogram[t]
+		if weightBackground == 0 {
+			continue
+		}
+		if weightBackground == total && bestVariance < 0 { //All pixels on same level
+			return byte(t)
+		}
+		weightForeground := total - weightBackground
+		if weightForeground == 0 {
+			break
+		}
+		sumBackground += float64(t * histogram[t])
+		meanBackground := sumBackground / float64(weightBackground)
+		meanForeground := (sum - sumBackground) / float64(weightForeground)
+		d := meanBackground - meanForeground
+		variance := float64(weightBackground) * float64(weightForeground) * d * d
+		if bestVariance < variance {
+			bestVariance = variance
+			best = t
+		}
+	}
+	return byte(best)
+}
+
+// adaptiveThreshold sets pixels of result brighter than local threshold
+func adaptiveThreshold(result *MonoBitmap, levels []float32, settings ImageConversion) {
+	w := result.W
+	h := result.H
+	window := settings.Window
+	if window <= 0 {
+		window = 15
+	}
+	radius := window / 2
+
+	var mean, deviation []float64
+	if settings.Method == THRESHOLD_GAUSSIAN {
+		mean = gaussianBlur(levels, w, h, 0.3*(float64(window-1)*0.5-1)+0.8)
+	} else {
+		mean, deviation = boxMeanDeviation(levels, w, h, radius)
+	}
+
+	k := settings.K
+	if k == 0 {
+		switch settings.Method {
+		case THRESHOLD_NIBLACK:
+			k = -0.2
+		case THRESHOLD_SAUVOLA:
+			k = 0.34
+		}
+	}
+
+	for i, v := range levels {
+		var t float64
+		switch settings.Method {
+		case THRESHOLD_NIBLACK:
+			t = mean[i] + k*deviation[i]
+		case THRESHOLD_SAUVOLA:
+			t = mean[i] * (1 + k*(deviation[i]/128-1))
+		default:
+			t = mean[i] - settings.Offset
+		}
+		result.SetPixNoCheck(i%w, i/w, t < float64(v))
+	}
+}
+
+// boxMeanDeviation calculates mean and standard deviation on (2*radius+1)^2 window with integral images. Window is clipped on edges
+func boxMeanDeviation(levels []float32, w int, h int, radius int) ([]float64, []float64) {
+	stride := w + 1
+	sum := make([]float64, stride*(h+1))
+	sumSq := make([]float64, stride*(h+1))
+	for y := 0; y < h; y++ {
+		rowSum := 0.0
+		rowSumSq := 0.0
+		for x := 0; x < w; x++ {
+			v := float64(levels[x+y*w])
+			rowSum += v
+			rowSumSq += v * v
+			sum[x+1+(y+1)*stride] = sum[x+1+y*stride] + rowSum
+			sumSq[x+1+(y+1)*stride] = sumSq[x+1+y*stride] + rowSumSq
+		}
+	}
+
+	mean := make([]float64, w*h)
+	deviation := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		y0 := max(0, y-radius)
+		y1 := min(h, y+radius+1)
+		for x := 0; x < w; x++ {
+			x0 := max(0, x-radius)
+			x1 := min(w, x+radius+1)
+			n := float64((x1 - x0) * (y1 - y0))
+			s := sum[x1+y1*stride] - sum[x0+y1*stride] - sum[x1+y0*stride] + sum[x0+y0*stride]
+			sq := sumSq[x1+y1*stride] - sumSq[x0+y1*stride] - sumSq[x1+y0*stride] + sumSq[x0+y0*stride]
+			m := s / n
+			mean[x+y*w] = m
+			deviation[x+y*w] = math.Sqrt(max(0, sq/n-m*m))
+		}
+	}
+	return mean, deviation
+}
+
+// gaussianBlur is separable gaussian filter. Edges are handled by normalizing weights inside image
+func gaussianBlur(levels []float32, w int, h int, sigma float64) []float64 {
+	radius := max(1, int(math.Ceil(3*sigma)))
+	kernel := make([]float64, 2*radius+1)
+	for i := range kernel {
+		d := float64(i - radius)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+	}
+
+	tmp := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			s, ws := 0.0, 0.0
+			for i, k := range kernel {
+				xx := x + i - radius
+				if 0 <= xx && xx < w {
+					s += k * float64(levels[xx+y*w])
+					ws += k
+				}
+			}
+			tmp[x+y*w] = s / ws
+		}
+	}
+	result := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			s, ws := 0.0, 0.0
+			for i, k := range kernel {
+				yy := y + i - radius
+				if 0 <= yy && yy < h {
+					s += k * tmp[x+yy*w]
+					ws += k
+				}
+			}
+			result[x+y*w] = s / ws
+		}
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+// Dark text on background getting brighter from left to right
+func unevenLabel() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 120, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 120; x++ {
+			bg := 40 + x*3/2
+			if (x/6)%2 == 0 && 15 <= y && y < 25 { //marks
+				bg -= 35
+			}
+			img.SetGray(x, y, color.Gray{Y: uint8(bg)})
+		}
+	}
+	return img
+}
+
+func TestOtsu(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 10, 10))
+	for i := range img.Pix {
+		img.Pix[i] = 50
+		if i%3 == 0 {
+			img.Pix[i] = 200
+		}
+	}
+	bm := gomonochromebitmap.ConvertImage(img, img.Bounds(), gomonochromebitmap.ImageConversion{Method: gomonochromebitmap.THRESHOLD_OTSU})
+	threshold := gomonochromebitmap.OtsuThreshold(img, img.Bounds(), gomonochromebitmap.LUMA_REC601)
+	if threshold < 50 || 200 <= threshold {
+		t.Errorf("otsu threshold %v not between classes", threshold)
+	}
+	if countOn(bm) != 34 {
+		t.Errorf("invalid number of on pixels %v", countOn(bm))
+	}
+
+	//Uniform image has no classes, its own level is threshold and all pixels are off
+	for _, level := range []uint8{0, 77, 255} {
+		for i := range img.Pix {
+			img.Pix[i] = level
+		}
+		if threshold := gomonochromebitmap.OtsuThreshold(img, img.Bounds(), gomonochromebitmap.LUMA_REC601); threshold != level {
+			t.Errorf("uniform level %v gave threshold %v", level, threshold)
+		}
+		if bm := gomonochromebitmap.ConvertImage(img, img.Bounds(), gomonochromebitmap.ImageConversion{Method: gomonochromebitmap.THRESHOLD_OTSU}); countOn(bm) != 0 {
+			t.Errorf("uniform level %v has %v pixels on", level, countOn(bm))
+		}
+	}
+}
+
+func TestAdaptiveThreshold(t *testing.T) {
+	img := unevenLabel()
+	for _, method := range []gomonochromebitmap.ConversionMethod{
+		gomonochromebitmap.THRESHOLD_MEAN,
+		gomonochromebitmap.THRESHOLD_GAUSSIAN,
+		gomonochromebitmap.THRESHOLD_NIBLACK,
+		gomonochromebitmap.THRESHOLD_SAUVOLA,
+	} {
+		settings := gomonochromebitmap.ImageConversion{Method: method, Window: 15, Offset: 5}
+		if method == gomonochromebitmap.THRESHOLD_SAUVOLA {
+			settings.K = 0.1 //Low contrast marks
+		}
+		bm := gomonochromebitmap.ConvertImage(img, img.Bounds(), settings)
+		//Centers of marks are off and background between marks is on, on both dark and bright ends
+		for _, x := range []int{15, 99} {
+			if bm.GetPix(x, 20) {
+				t.Errorf("method %v: mark at x=%v not detected", method, x)
+			}
+			if !bm.GetPix(x+6, 20) || !bm.GetPix(x, 5) {
+				t.Errorf("method %v: background at x=%v not detected", method, x)
+			}
+		}
+	}
+
+}
+
+func TestNewMonoBitmapFromImageArea(t *testing.T) {
+	img := unevenLabel()
+	bm := gomonochromebitmap.NewMonoBitmapFromImage(img, image.Rect(100, 10, 120, 30), 150, false)
+	if bm.W != 20 || bm.H != 20 {
+		t.Fatalf("area ignored, got %vx%v", bm.W, bm.H)
+	}
+	for y := 0; y < bm.H; y++ {
+		for x := 0; x < bm.W; x++ {
+			v, _, _, _ := img.At(x+100, y+10).RGBA()
+			if bm.GetPix(x, y) != (150 < v>>8) {
+				t.Fatalf("pixel %v,%v not converted", x, y)
+			}
+		}
+	}
+}
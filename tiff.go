@@ -0,0 +1,270 @@
+/*
+Bilevel TIFF reading and writing
+
+Supports uncompressed, CCITT modified huffman, Group 3 and Group 4 compressed
+single bit images. Only first image of file is read.
+*/
+package gomonochromebitmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+)
+
+const (
+	tiffImageWidth                = 256
+	tiffImageLength               = 257
+	tiffBitsPerSample             = 258
+	tiffCompression               = 259
+	tiffPhotometricInterpretation = 262
+	tiffFillOrder                 = 266
+	tiffStripOffsets              = 273
+	tiffSamplesPerPixel           = 277
+	tiffRowsPerStrip              = 278
+	tiffStripByteCounts           = 279
+	tiffXResolution               = 282
+	tiffYResolution               = 283
+	tiffT4Options                 = 292
+	tiffT6Options                 = 293
+	tiffResolutionUnit            = 296
+
+	tiffCompressionNone = 1
+)
+
+// tiffTypeSize is size of field types BYTE,ASCII,SHORT,LONG,RATIONAL
+var tiffTypeSize = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8}
+
+// readTIFFIFD reads tags of first image. Returns values of SHORT and LONG tags
+func readTIFFIFD(data []byte) (map[uint16][]uint32, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("too short TIFF file")
+	}
+	var order binary.ByteOrder
+	switch string(data[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("not TIFF file")
+	}
+	if order.Uint16(data[2:4]) != 42 {
+		return nil, fmt.Errorf("invalid TIFF version")
+	}
+	offset := int(order.Uint32(data[4:8]))
+	if offset+2 > len(data) {
+		return nil, fmt.Errorf("TIFF IFD offset out of file")
+	}
+	n := int(order.Uint16(data[offset:]))
+	if offset+2+n*12 > len(data) {
+		return nil, fmt.Errorf("TIFF IFD out of file")
+	}
+
+	result := make(map[uint16][]uint32)
+	for i := 0; i < n; i++ {
+		entry := data[offset+2+i*12 : offset+2+i*12+12]
+		tag := order.Uint16(entry[0:2])
+		typ := order.Uint16(entry[2:4])
+		count := int(order.Uint32(entry[4:8]))
+		size, known := tiffTypeSize[typ]
+		if !known || (typ != 3 && typ != 4) {
+			continue //Only integer values are needed
+		}
+		values := entry[8:12]
+		if 4 < size*count {
+			valueOffset := int(order.Uint32(entry[8:12]))
+			if valueOffset < 0 || len(data) < valueOffset+size*count {
+				return nil, fmt.Errorf("TIFF tag %v values out of file", tag)
+			}
+			values = data[valueOffset : valueOffset+size*count]
+		}
+		list := make([]uint32, count)
+		for j := range list {
+			if typ == 3 {
+				list[j] = uint32(order.Uint16(values[j*2:]))
+			} else {
+				list[j] = order.Uint32(values[j*4:])
+			}
+		}
+		result[tag] = list
+	}
+	return result, nil
+}
+
+// tiffTag returns first value of tag or default
+func tiffTag(tags map[uint16][]uint32, tag uint16, def uint32) uint32 {
+	if v, ok := tags[tag]; ok && 0 < len(v) {
+		return v[0]
+	}
+	return def
+}
+
+// DecodeTIFF reads first image of bilevel TIFF file
+func DecodeTIFF(r io.Reader) (MonoBitmap, error) {
+	data, errRead := io.ReadAll(r)
+	if errRead != nil {
+		return MonoBitmap{}, errRead
+	}
+	tags, errIFD := readTIFFIFD(data)
+	if errIFD != nil {
+		return MonoBitmap{}, errIFD
+	}
+	w := int(tiffTag(tags, tiffImageWidth, 0))
+	h := int(tiffTag(tags, tiffImageLength, 0))
+	if w <= 0 || h <= 0 {
+		return MonoBitmap{}, fmt.Errorf("invalid TIFF dimensions %vx%v", w, h)
+	}
+	if errSize := checkDecodeSize(w, h); errSize != nil {
+		return MonoBitmap{}, fmt.Errorf("TIFF header: %w", errSize)
+	}
+	if tiffTag(tags, tiffBitsPerSample, 1) != 1 || tiffTag(tags, tiffSamplesPerPixel, 1) != 1 {
+		return MonoBitmap{}, fmt.Errorf("TIFF is not bilevel image")
+	}
+	compression := tiffTag(tags, tiffCompression, tiffCompressionNone)
+	photometric := tiffTag(tags, tiffPhotometricInterpretation, 0)
+	rowsPerStrip := int(tiffTag(tags, tiffRowsPerStrip, uint32(h)))
+	t4 := tiffTag(tags, tiffT4Options, 0)
+	opt := CCITTOptions{
+		Mode:     CCITTMode(compression),
+		LSBFirst: tiffTag(tags, tiffFillOrder, 1) == 2,
+		AlignEOL: t4&4 != 0,
+	}
+	if t4&1 != 0 {
+		opt.K = 2
+	}
+
+	offsets := tags[tiffStripOffsets]
+	counts := tags[tiffStripByteCounts]
+	if len(offsets) == 0 || len(offsets) != len(counts) || rowsPerStrip <= 0 {
+		return MonoBitmap{}, fmt.Errorf("invalid TIFF strips")
+	}
+
+	result := NewMonoBitmap(w, h, false)
+	for i, off := range offsets {
+		if len(data) < int(off)+int(counts[i]) {
+			return MonoBitmap{}, fmt.Errorf("TIFF strip %v out of file", i)
+		}
+		strip := data[off : off+counts[i]]
+		y0 := i * rowsPerStrip
+		rows := min(rowsPerStrip, h-y0)
+		if rows <= 0 {
+			break
+		}
+
+		var stripBitmap MonoBitmap
+		var err error
+		switch CCITTMode(compression) {
+		case CCITT_MH, CCITT_G3, CCITT_G4:
+			stripBitmap, err = DecodeCCITT(strip, w, rows, opt)
+		case tiffCompressionNone:
+			//Bit 0 is white (on) with WhiteIsZero, BlackIsZero is handled after all strips
+			stripBitmap, err = UnpackBytes(ccittFillOrder(strip, opt.LSBFirst), w, rows, PACK_HORIZONTAL, MSB_FIRST)
+			stripBitmap.Invert(stripBitmap.LocalBounds())
+		default:
+			return MonoBitmap{}, fmt.Errorf("unsupported TIFF compression %v", compression)
+		}
+		if err != nil {
+			return MonoBitmap{}, fmt.Errorf("TIFF strip %v: %w", i, err)
+		}
+		result.DrawBitmapOp(stripBitmap, stripBitmap.LocalBounds(), image.Point{X: 0, Y: y0}, ROP_COPY)
+	}
+	if photometric == 1 { //BlackIsZero, bit 0 is black
+		result.Invert(result.LocalBounds())
+	}
+	return result, nil
+}
+
+// tiffEntry is IFD entry having single value
+type tiffEntry struct {
+	tag   uint16
+	typ   uint16
+	value uint32
+}
+
+// EncodeTIFF writes bitmap as single strip TIFF. Mode CCITT_G4 gives smallest files, zero mode writes uncompressed data
+func EncodeTIFF(w io.Writer, bm *MonoBitmap, opt CCITTOptions) error {
+	var strip []byte
+	compression := uint32(tiffCompressionNone)
+	switch opt.Mode {
+	case CCITT_MH, CCITT_G3, CCITT_G4:
+		strip = EncodeCCITT(bm, opt)
+		compression = uint32(opt.Mode)
+	default:
+		inverted := bm.Clone() //WhiteIsZero, on pixels are 0
+		inverted.Invert(inverted.LocalBounds())
+		strip = ccittFillOrder(inverted.PackBytes(PACK_HORIZONTAL, MSB_FIRST), opt.LSBFirst)
+	}
+	fillOrder := uint32(1)
+	if opt.LSBFirst {
+		fillOrder = 2
+	}
+
+	entries := []tiffEntry{
+		{tiffImageWidth, 4, uint32(bm.W)},
+		{tiffImageLength, 4, uint32(bm.H)},
+		{tiffBitsPerSample, 3, 1},
+		{tiffCompression, 3, compression},
+		{tiffPhotometricInterpretation, 3, 0}, //WhiteIsZero
+		{tiffFillOrder, 3, fillOrder},
+		{tiffStripOffsets, 4, 0}, //Filled later
+		{tiffSamplesPerPixel, 3, 1},
+		{tiffRowsPerStrip, 4, uint32(bm.H)},
+		{tiffStripByteCounts, 4, uint32(len(strip))},
+		{tiffXResolution, 5, 0}, //Offset filled later
+		{tiffYResolution, 5, 0},
+	}
+	switch opt.Mode {
+	case CCITT_G3:
+		t4 := uint32(0)
+		if 1 < opt.K {
+			t4 |= 1
+		}
+		if opt.AlignEOL {
+			t4 |= 4
+		}
+		entries = append(entries, tiffEntry{tiffT4Options, 4, t4})
+	case CCITT_G4:
+		entries = append(entries, tiffEntry{tiffT6Options, 4, 0})
+	}
+	entries = append(entries, tiffEntry{tiffResolutionUnit, 3, 2}) //Inch
+
+	ifdOffset := 8
+	ifdSize := 2 + 12*len(entries) + 4
+	resolutionOffset := ifdOffset + ifdSize
+	stripOffset := resolutionOffset + 16
+
+	var buf bytes.Buffer
+	order := binary.LittleEndian
+	buf.WriteString("II")
+	binary.Write(&buf, order, uint16(42))
+	binary.Write(&buf, order, uint32(ifdOffset))
+	binary.Write(&buf, order, uint16(len(entries)))
+	for _, e := range entries {
+		switch e.tag {
+		case tiffStripOffsets:
+			e.value = uint32(stripOffset)
+		case tiffXResolution:
+			e.value = uint32(resolutionOffset)
+		case tiffYResolution:
+			e.value = uint32(resolutionOffset + 8)
+		}
+		binary.Write(&buf, order, e.tag)
+		binary.Write(&buf, order, e.typ)
+		binary.Write(&buf, order, uint32(1))
+		if e.typ == 3 {
+			binary.Write(&buf, order, uint16(e.value))
+			binary.Write(&buf, order, uint16(0))
+		} else {
+			binary.Write(&buf, order, e.value)
+		}
+	}
+	binary.Write(&buf, order, uint32(0)) //No next IFD
+	binary.Write(&buf, order, [4]uint32{200, 1, 200, 1})
+	buf.Write(strip)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,118 @@
+package gomonochromebitmap_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestTIFFRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(11))
+	modes := []gomonochromebitmap.CCITTOptions{
+		{}, //Uncompressed
+		{LSBFirst: true},
+		{Mode: gomonochromebitmap.CCITT_MH},
+		{Mode: gomonochromebitmap.CCITT_G3, AlignEOL: true},
+		{Mode: gomonochromebitmap.CCITT_G3, K: 4},
+		{Mode: gomonochromebitmap.CCITT_G4},
+		{Mode: gomonochromebitmap.CCITT_G4, LSBFirst: true},
+	}
+	for _, opt := range modes {
+		for _, w := range []int{1, 9, 100} {
+			bm := runBitmap(rnd, w, 13, 6)
+			var buf bytes.Buffer
+			if err := gomonochromebitmap.EncodeTIFF(&buf, &bm, opt); err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := gomonochromebitmap.DecodeTIFF(&buf)
+			if err != nil {
+				t.Fatalf("%+v w=%v: %v", opt, w, err)
+			}
+			if !equalBitmaps(bm, decoded) {
+				t.Errorf("%+v w=%v round trip failed", opt, w)
+			}
+		}
+	}
+}
+
+// Hand made big endian BlackIsZero file having two strips
+func TestTIFFDecodeStrips(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("MM\x00\x2a\x00\x00\x00\x08")
+	entries := [][3]uint32{
+		{256, 3, 4},   //Width
+		{257, 3, 3},   //Height
+		{259, 3, 1},   //No compression
+		{262, 3, 1},   //BlackIsZero
+		{273, 4, 100}, //Strip offsets at 100
+		{278, 3, 2},   //Rows per strip
+		{279, 4, 108}, //Strip byte counts at 108
+	}
+	buf.Write([]byte{0, byte(len(entries))})
+	for _, e := range entries {
+		count := uint32(1)
+		if e[0] == 273 || e[0] == 279 {
+			count = 2
+		}
+		value := e[2]
+		buf.Write([]byte{byte(e[0] >> 8), byte(e[0]), 0, byte(e[1]), 0, 0, 0, byte(count)})
+		if e[1] == 3 && count == 1 {
+			buf.Write([]byte{byte(value >> 8), byte(value), 0, 0})
+		} else {
+			buf.Write([]byte{0, 0, 0, byte(value)})
+		}
+	}
+	buf.Write([]byte{0, 0, 0, 0})
+	buf.Write(make([]byte, 100-buf.Len()))
+	buf.Write([]byte{0, 0, 0, 116, 0, 0, 0, 118}) //Offsets 116 and 118
+	buf.Write([]byte{0, 0, 0, 2, 0, 0, 0, 1})     //Counts 2 and 1
+	buf.Write([]byte{0x90, 0x60, 0xF0})           //Rows 1001, 0110, 1111
+
+	bm, err := gomonochromebitmap.DecodeTIFF(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"#..#", ".##.", "####"}
+	for y, row := range expected {
+		for x, c := range row {
+			if bm.GetPix(x, y) != (c == '#') {
+				t.Errorf("pixel %v,%v invalid", x, y)
+			}
+		}
+	}
+}
+
+func TestTIFFInvalid(t *testing.T) {
+	for _, data := range []string{"", "II*\x00", "XX*\x00\x08\x00\x00\x00", "II*\x00\xff\x00\x00\x00"} {
+		if _, err := gomonochromebitmap.DecodeTIFF(bytes.NewReader([]byte(data))); err == nil {
+			t.Errorf("no error on %q", data)
+		}
+	}
+}
+
+func TestTIFFHostileSize(t *testing.T) {
+	bm := gomonochromebitmap.NewMonoBitmap(8, 2, false)
+	for _, tag := range []uint16{256, 257} { //ImageWidth and ImageLength
+		for _, size := range []uint32{0, 0xFFFFFFFF, 0x80000000} {
+			var buf bytes.Buffer
+			if err := gomonochromebitmap.EncodeTIFF(&buf, &bm, gomonochromebitmap.CCITTOptions{Mode: gomonochromebitmap.CCITT_G4}); err != nil {
+				t.Fatal(err)
+			}
+			data := buf.Bytes()
+			ifd := int(binary.LittleEndian.Uint32(data[4:]))
+			for i := 0; i < int(binary.LittleEndian.Uint16(data[ifd:])); i++ {
+				entry := data[ifd+2+12*i:]
+				if binary.LittleEndian.Uint16(entry) == tag {
+					binary.LittleEndian.PutUint16(entry[2:], 4) //LONG
+					binary.LittleEndian.PutUint32(entry[8:], size)
+				}
+			}
+			if _, err := gomonochromebitmap.DecodeTIFF(bytes.NewReader(data)); err == nil {
+				t.Errorf("tag %v size %v no error", tag, size)
+			}
+		}
+	}
+}
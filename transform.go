@@ -0,0 +1,144 @@
+/*
+Affine transforms
+
+Affine matrix maps source coordinates into target coordinates. Drawing is
+done backwards, each target pixel is mapped into source with inverted matrix.
+Pixel x,y covers area x...x+1, y...y+1 so pixel centers are at x+0.5, y+0.5.
+
+Y axis points down, so positive rotation angle turns clockwise on screen like
+Rotate90.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+	"math"
+)
+
+// Affine is matrix x' = A[0]*x + A[1]*y + A[2], y' = A[3]*x + A[4]*y + A[5]
+type Affine [6]float64
+
+// IdentityAffine does not change coordinates
+var IdentityAffine = Affine{1, 0, 0, 0, 1, 0}
+
+// Sampling selects how target pixel value is picked from source
+type Sampling byte
+
+const (
+	SAMPLE_NEAREST  Sampling = 0 // Source pixel under target pixel center
+	SAMPLE_MAJORITY Sampling = 1 // Area of target pixel is supersampled from source, on when at least half of samples are on
+)
+
+// Rotation turns clockwise around origin, angle is in radians
+func Rotation(angle float64) Affine {
+	sin, cos := math.Sincos(angle)
+	return Affine{cos, -sin, 0, sin, cos, 0}
+}
+
+// RotationAround turns clockwise around point c (in pixel coordinates, use 0.5 offset for pixel center)
+func RotationAround(cx float64, cy float64, angle float64) Affine {
+	return Translation(cx, cy).Mul(Rotation(angle)).Mul(Translation(-cx, -cy))
+}
+
+// Scaling multiplies coordinates
+func Scaling(sx float64, sy float64) Affine {
+	return Affine{sx, 0, 0, 0, sy, 0}
+}
+
+// Shearing moves x by shx*y and y by shy*x
+func Shearing(shx float64, shy float64) Affine {
+	return Affine{1, shx, 0, shy, 1, 0}
+}
+
+// Translation moves coordinates
+func Translation(dx float64, dy float64) Affine {
+	return Affine{1, 0, dx, 0, 1, dy}
+}
+
+// Mul returns matrix that applies b first and then a
+func (a Affine) Mul(b Affine) Affine {
+	return Affine{
+		a[0]*b[0] + a[1]*b[3], a[0]*b[1] + a[1]*b[4], a[0]*b[2] + a[1]*b[5] + a[2],
+		a[3]*b[0] + a[4]*b[3], a[3]*b[1] + a[4]*b[4], a[3]*b[2] + a[4]*b[5] + a[5],
+	}
+}
+
+// Apply maps point
+func (a Affine) Apply(x float64, y float64) (float64, float64) {
+	return a[0]*x + a[1]*y + a[2], a[3]*x + a[4]*y + a[5]
+}
+
+// Invert returns inverse matrix. False if matrix is singular
+func (a Affine) Invert() (Affine, bool) {
+	det := a[0]*a[4] - a[1]*a[3]
+	if math.Abs(det) < 1e-12 {
+		return Affine{}, false
+	}
+	return Affine{
+		a[4] / det, -a[1] / det, (a[1]*a[5] - a[4]*a[2]) / det,
+		-a[3] / det, a[0] / det, (a[3]*a[2] - a[0]*a[5]) / det,
+	}, true
+}
+
+// TransformedBounds returns bounding box of transformed rectangle r
+func (a Affine) TransformedBounds(r image.Rectangle) image.Rectangle {
+	const eps = 1e-9 //Rounding errors should not add extra row or column
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, c := range []image.Point{r.Min, {r.Max.X, r.Min.Y}, {r.Min.X, r.Max.Y}, r.Max} {
+		x, y := a.Apply(float64(c.X), float64(c.Y))
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+	return image.Rect(int(math.Floor(minX+eps)), int(math.Floor(minY+eps)), int(math.Ceil(maxX-eps)), int(math.Ceil(maxY-eps)))
+}
+
+// Transform returns transformed copy of source. Result is just large enough for transformed source, returned point is position of result corner on matrix coordinates
+func Transform(src *MonoBitmap, m Affine, sampling Sampling) (MonoBitmap, image.Point) {
+	bounds := m.TransformedBounds(src.LocalBounds())
+	result := NewMonoBitmap(bounds.Dx(), bounds.Dy(), false)
+	result.DrawTransformed(src, Translation(float64(-bounds.Min.X), float64(-bounds.Min.Y)).Mul(m), sampling, true, false)
+	return result, bounds.Min
+}
+
+// DrawTransformed draws source mapped with matrix. drawTrue and drawFalse select are on and off pixels of source drawn, like on DrawBitmap
+func (p *MonoBitmap) DrawTransformed(src *MonoBitmap, m Affine, sampling Sampling, drawTrue bool, drawFalse bool) {
+	inv, ok := m.Invert()
+	if !ok || src.W == 0 || src.H == 0 {
+		return
+	}
+	area := m.TransformedBounds(src.LocalBounds()).Intersect(p.LocalBounds())
+
+	//Supersampling grid is sized by how many source pixels one target pixel covers
+	n := 1
+	if sampling == SAMPLE_MAJORITY {
+		step := math.Max(math.Hypot(inv[0], inv[3]), math.Hypot(inv[1], inv[4]))
+		n = min(16, max(3, int(math.Ceil(2*step))))
+	}
+
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			inside, on := 0, 0
+			for j := 0; j < n; j++ {
+				for i := 0; i < n; i++ {
+					sx, sy := inv.Apply(float64(x)+(float64(i)+0.5)/float64(n), float64(y)+(float64(j)+0.5)/float64(n))
+					ix, iy := int(math.Floor(sx)), int(math.Floor(sy))
+					if ix < 0 || iy < 0 || src.W <= ix || src.H <= iy {
+						continue
+					}
+					inside++
+					if src.GetPixNoCheck(ix, iy) {
+						on++
+					}
+				}
+			}
+			if inside == 0 {
+				continue
+			}
+			value := n*n <= 2*on //Samples outside of source are off
+			if (value && drawTrue) || (!value && drawFalse) {
+				p.SetPixNoCheck(x, y, value)
+			}
+		}
+	}
+}
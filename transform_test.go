@@ -0,0 +1,112 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestTransformRightAngles(t *testing.T) {
+	src := randomBitmap(rand.New(rand.NewSource(28)), 37, 21)
+	for _, sampling := range []gomonochromebitmap.Sampling{gomonochromebitmap.SAMPLE_NEAREST, gomonochromebitmap.SAMPLE_MAJORITY} {
+		result, corner := gomonochromebitmap.Transform(&src, gomonochromebitmap.IdentityAffine, sampling)
+		if corner != (image.Point{}) || !equalBitmaps(src, result) {
+			t.Errorf("identity failed with sampling %v", sampling)
+		}
+		for turn := 1; turn < 4; turn++ {
+			expected := src.Clone()
+			expected.Rotate90(turn)
+			result, _ = gomonochromebitmap.Transform(&src, gomonochromebitmap.Rotation(float64(turn)*math.Pi/2), sampling)
+			if !equalBitmaps(expected, result) {
+				t.Errorf("rotation %v*90 failed with sampling %v", turn, sampling)
+			}
+		}
+	}
+	_, corner := gomonochromebitmap.Transform(&src, gomonochromebitmap.Rotation(math.Pi/2), gomonochromebitmap.SAMPLE_NEAREST)
+	if corner != image.Pt(-21, 0) {
+		t.Errorf("rotation corner %v", corner)
+	}
+}
+
+func TestTransformScale(t *testing.T) {
+	src := randomBitmap(rand.New(rand.NewSource(29)), 13, 9)
+	result, _ := gomonochromebitmap.Transform(&src, gomonochromebitmap.Scaling(2, 3), gomonochromebitmap.SAMPLE_NEAREST)
+	if result.W != 26 || result.H != 27 {
+		t.Fatalf("size %vx%v", result.W, result.H)
+	}
+	for y := 0; y < result.H; y++ {
+		for x := 0; x < result.W; x++ {
+			if result.GetPixNoCheck(x, y) != src.GetPixNoCheck(x/2, y/3) {
+				t.Fatalf("scale up failed at %v,%v", x, y)
+			}
+		}
+	}
+
+	//Halving picks majority of 2x2 blocks. Single pixels drop out, filled blocks stay
+	src = gomonochromebitmap.NewMonoBitmap(8, 8, false)
+	src.SetPix(0, 0, true)
+	src.Fill(image.Rect(4, 4, 5, 5), true)
+	src.Fill(image.Rect(0, 6, 7, 6), true)
+	result, _ = gomonochromebitmap.Transform(&src, gomonochromebitmap.Scaling(0.5, 0.5), gomonochromebitmap.SAMPLE_MAJORITY)
+	expected := gomonochromebitmap.NewMonoBitmap(4, 4, false)
+	expected.SetPix(2, 2, true)
+	expected.Fill(image.Rect(0, 3, 3, 3), true)
+	if !equalBitmaps(expected, result) {
+		t.Errorf("majority downscale failed")
+	}
+}
+
+func TestTransformShearAndRotate(t *testing.T) {
+	src := gomonochromebitmap.NewMonoBitmap(1, 10, false)
+	src.Fill(src.Bounds(), true)
+	result, corner := gomonochromebitmap.Transform(&src, gomonochromebitmap.Shearing(1, 0), gomonochromebitmap.SAMPLE_NEAREST)
+	if result.W != 11 || result.H != 10 || corner != (image.Point{}) {
+		t.Fatalf("shear size %vx%v at %v", result.W, result.H, corner)
+	}
+	for y := 0; y < 10; y++ {
+		if !result.GetPix(y, y) || result.GetPix(y+2, y) || countOn(result) != 10 {
+			t.Fatalf("shear failed on row %v", y)
+		}
+	}
+
+	//Rotated square keeps its area
+	square := gomonochromebitmap.NewMonoBitmap(40, 40, true)
+	for _, sampling := range []gomonochromebitmap.Sampling{gomonochromebitmap.SAMPLE_NEAREST, gomonochromebitmap.SAMPLE_MAJORITY} {
+		result, _ = gomonochromebitmap.Transform(&square, gomonochromebitmap.Rotation(math.Pi/4), sampling)
+		if math.Abs(float64(countOn(result))-1600) > 60 {
+			t.Errorf("sampling %v rotated area %v", sampling, countOn(result))
+		}
+	}
+
+	if result, _ = gomonochromebitmap.Transform(&square, gomonochromebitmap.Scaling(0, 1), gomonochromebitmap.SAMPLE_NEAREST); countOn(result) != 0 {
+		t.Errorf("singular matrix drew pixels")
+	}
+}
+
+func TestDrawTransformed(t *testing.T) {
+	needle := gomonochromebitmap.NewMonoBitmap(3, 20, false)
+	needle.Fill(image.Rect(1, 0, 1, 19), true)
+
+	//Needle pointing up from dial center, turned to 3 o'clock
+	dial := gomonochromebitmap.NewMonoBitmap(64, 64, false)
+	m := gomonochromebitmap.RotationAround(32.5, 32.5, math.Pi/2).Mul(gomonochromebitmap.Translation(31, 12))
+	dial.DrawTransformed(&needle, m, gomonochromebitmap.SAMPLE_NEAREST, true, false)
+	for x := 33; x < 53; x++ {
+		if !dial.GetPix(x, 32) {
+			t.Errorf("needle missing at %v", x)
+		}
+	}
+	if countOn(dial) != 20 {
+		t.Errorf("needle has %v pixels", countOn(dial))
+	}
+
+	//drawFalse clears area under needle
+	dial.Fill(dial.Bounds(), true)
+	dial.DrawTransformed(&needle, m, gomonochromebitmap.SAMPLE_NEAREST, false, true)
+	if countOn(dial) != 64*64-40 {
+		t.Errorf("cleared %v pixels", 64*64-countOn(dial))
+	}
+}
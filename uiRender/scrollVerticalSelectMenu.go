@@ -20,7 +20,7 @@ func GetStringBitmaps(arr []string, font map[rune]gomonochromebitmap.MonoBitmap,
 	result := make([]gomonochromebitmap.MonoBitmap, len(arr))
 	workArea := gomonochromebitmap.NewMonoBitmap(w, h, false)
 	for i := 0; i < len(arr); i++ {
-		usedRect := workArea.Print(arr[i], font, lineSpacing, gap, workArea.Bounds(), true, true, false, true)
+		usedRect := workArea.Print(arr[i], font, lineSpacing, gap, workArea.LocalBounds(), true, true, false, true)
 		usedRect.Max.X = w
 		usedRect.Max.Y = lineSpacing
 		//Shrink on vertical
@@ -32,6 +32,16 @@ func GetStringBitmaps(arr []string, font map[rune]gomonochromebitmap.MonoBitmap,
 }
 
 func (p *ScrollVerticalSelectMenu) Render(w int, h int) gomonochromebitmap.MonoBitmap {
+	result := gomonochromebitmap.NewMonoBitmap(w, h, false)
+	p.RenderOn(&result)
+	return result
+}
+
+// RenderOn renders menu on target. Target can be SubBitmap view of larger framebuffer
+func (p *ScrollVerticalSelectMenu) RenderOn(result *gomonochromebitmap.MonoBitmap) {
+	w := result.W
+	h := result.H
+	result.Fill(result.LocalBounds(), false)
 	if p.SelectedIndex < 0 {
 		p.SelectedIndex = 0
 	}
@@ -55,8 +65,6 @@ func (p *ScrollVerticalSelectMenu) Render(w int, h int) gomonochromebitmap.MonoB
 	barHeight := h * h / totalHeightCounter
 	barStart := h * p.Scroll / totalHeightCounter
 
-	result := gomonochromebitmap.NewMonoBitmap(w, h, false)
-
 	leftMargin := 0 //TODO set Arrow Width
 	//Ok, render bitmaps
 	totalHeightCounter = 0
@@ -93,5 +101,4 @@ func (p *ScrollVerticalSelectMenu) Render(w int, h int) gomonochromebitmap.MonoB
 		}
 
 	}
-	return result
 }
@@ -139,7 +139,7 @@ func (p *TableSetting) Render(target gomonochromebitmap.MonoBitmap) error {
 	}
 	//ok, dimensions are known. Lets draw
 	//Clear bitmap?
-	target.Fill(target.Bounds(), false)
+	target.Fill(target.LocalBounds(), false)
 
 	target.Fill(image.Rect(0, 0, 0, p.VLines), true) //left side
 	target.Fill(image.Rect(totalw, 0, totalw, p.VLines), true)
@@ -5,6 +5,7 @@ package uirender
 
 import (
 	"fmt"
+	"image"
 	"image/color"
 	"image/png"
 	"os"
@@ -96,3 +97,30 @@ func TestSimple(t *testing.T) {
 	largeout2.Close()
 
 }
+
+func TestRenderOnView(t *testing.T) {
+	testfont1 := gomonochromebitmap.GetFont_8x8()
+	menu := ScrollVerticalSelectMenu{
+		Bitmaps:         GetStringBitmaps([]string{"Alpha", "Bravo", "Charlie", "Delta", "Echo"}, testfont1, 60, 32, 8, 1),
+		SelectedIndex:   1,
+		InvertSelection: true,
+		ScrollBar:       1,
+	}
+	expected := menu.Render(61, 30)
+
+	framebuffer := gomonochromebitmap.NewMonoBitmap(128, 64, true)
+	view := framebuffer.SubBitmap(image.Rect(37, 20, 98, 50))
+	menu.RenderOn(&view)
+
+	for y := 0; y < framebuffer.H; y++ {
+		for x := 0; x < framebuffer.W; x++ {
+			want := true
+			if 37 <= x && x < 98 && 20 <= y && y < 50 {
+				want = expected.GetPix(x-37, y-20)
+			}
+			if framebuffer.GetPix(x, y) != want {
+				t.Fatalf("pixel %v,%v differs", x, y)
+			}
+		}
+	}
+}
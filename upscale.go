@@ -0,0 +1,212 @@
+/*
+Pixel art upscalers
+
+Scale2x (same as EPX) and Scale3x look at 3x3 neighbourhood of each pixel and
+round corners where neighbours form diagonal edge. Smooth upscaler does same
+kind of edge detection as Scale2x, but cuts corners along diagonal on any
+scale factor, like hqx does.
+
+Pixels outside bitmap are treated as copies of nearest edge pixel.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+)
+
+// Upscaler selects algorithm used on zooming in
+type Upscaler byte
+
+const (
+	UPSCALE_NEAREST Upscaler = 0 // Each pixel becomes block
+	UPSCALE_SCALENX Upscaler = 1 // Factor is split to Scale3x and Scale2x steps, leftover factor is done with nearest
+	UPSCALE_SMOOTH  Upscaler = 2 // hqx style diagonal smoothing, any factor
+)
+
+// neighbour returns pixel with coordinates clamped inside bitmap
+func (p *MonoBitmap) neighbour(x int, y int) bool {
+	return p.GetPixNoCheck(min(max(x, 0), p.W-1), min(max(y, 0), p.H-1))
+}
+
+// Scale returns copy where each pixel is n pixels wide and m pixels high
+func (p *MonoBitmap) Scale(n int, m int) MonoBitmap {
+	n, m = max(n, 1), max(m, 1)
+	result := NewMonoBitmap(p.W*n, p.H*m, false)
+	for y := 0; y < p.H; y++ {
+		y0 := y * m
+		for x := 0; x < p.W; x++ {
+			if p.GetPixNoCheck(x, y) {
+				result.Hline(x*n, x*n+n-1, y0, true)
+			}
+		}
+		for j := 1; j < m; j++ { //Rest of rows are copies
+			blitBits(result.Pix, result.rowBit(y0+j), result.Pix, result.rowBit(y0), result.W, ROP_COPY)
+		}
+	}
+	return result
+}
+
+// Scale2x doubles size and rounds diagonal edges. Also known as AdvMAME2x
+func (p *MonoBitmap) Scale2x() MonoBitmap {
+	result := NewMonoBitmap(p.W*2, p.H*2, false)
+	for y := 0; y < p.H; y++ {
+		for x := 0; x < p.W; x++ {
+			b, d, e, f, h := p.neighbour(x, y-1), p.neighbour(x-1, y), p.GetPixNoCheck(x, y), p.neighbour(x+1, y), p.neighbour(x, y+1)
+			e0, e1, e2, e3 := e, e, e, e
+			if b != h && d != f {
+				if d == b {
+					e0 = d
+				}
+				if b == f {
+					e1 = f
+				}
+				if d == h {
+					e2 = d
+				}
+				if h == f {
+					e3 = f
+				}
+			}
+			result.SetPixNoCheck(2*x, 2*y, e0)
+			result.SetPixNoCheck(2*x+1, 2*y, e1)
+			result.SetPixNoCheck(2*x, 2*y+1, e2)
+			result.SetPixNoCheck(2*x+1, 2*y+1, e3)
+		}
+	}
+	return result
+}
+
+// EPX is original name of Scale2x algorithm, results are identical
+func (p *MonoBitmap) EPX() MonoBitmap {
+	return p.Scale2x()
+}
+
+// Scale3x triples size and rounds diagonal edges. Also known as AdvMAME3x
+func (p *MonoBitmap) Scale3x() MonoBitmap {
+	result := NewMonoBitmap(p.W*3, p.H*3, false)
+	for y := 0; y < p.H; y++ {
+		for x := 0; x < p.W; x++ {
+			a, b, c := p.neighbour(x-1, y-1), p.neighbour(x, y-1), p.neighbour(x+1, y-1)
+			d, e, f := p.neighbour(x-1, y), p.GetPixNoCheck(x, y), p.neighbour(x+1, y)
+			g, h, i := p.neighbour(x-1, y+1), p.neighbour(x, y+1), p.neighbour(x+1, y+1)
+			out := [9]bool{e, e, e, e, e, e, e, e, e}
+			if b != h && d != f {
+				if d == b {
+					out[0] = d
+				}
+				if (d == b && e != c) || (b == f && e != a) {
+					out[1] = b
+				}
+				if b == f {
+					out[2] = f
+				}
+				if (d == b && e != g) || (d == h && e != a) {
+					out[3] = d
+				}
+				if (b == f && e != i) || (h == f && e != c) {
+					out[5] = f
+				}
+				if d == h {
+					out[6] = d
+				}
+				if (d == h && e != i) || (h == f && e != g) {
+					out[7] = h
+				}
+				if h == f {
+					out[8] = f
+				}
+			}
+			for k, v := range out {
+				result.SetPixNoCheck(3*x+k%3, 3*y+k/3, v)
+			}
+		}
+	}
+	return result
+}
+
+// SmoothScale scales by factor n. Corners are cut along diagonal where Scale2x would round them
+func (p *MonoBitmap) SmoothScale(n int) MonoBitmap {
+	n = max(n, 1)
+	result := p.Scale(n, n)
+	for y := 0; y < p.H; y++ {
+		for x := 0; x < p.W; x++ {
+			b, d, e, f, h := p.neighbour(x, y-1), p.neighbour(x-1, y), p.GetPixNoCheck(x, y), p.neighbour(x+1, y), p.neighbour(x, y+1)
+			if b == h || d == f {
+				continue
+			}
+			//Corners by their direction, flipping coordinates inside block
+			corners := []struct {
+				cut    bool
+				value  bool
+				fx, fy bool
+			}{
+				{d == b && b != e, d, false, false},
+				{b == f && b != e, f, true, false},
+				{d == h && d != e, d, false, true},
+				{h == f && h != e, f, true, true},
+			}
+			for _, c := range corners {
+				if !c.cut {
+					continue
+				}
+				for j := 0; 2*(j+1) <= n; j++ {
+					for i := 0; 2*(i+j+1) <= n; i++ {
+						px, py := i, j
+						if c.fx {
+							px = n - 1 - i
+						}
+						if c.fy {
+							py = n - 1 - j
+						}
+						result.SetPixNoCheck(x*n+px, y*n+py, c.value)
+					}
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Upscale scales by integer factor using selected method
+func (p *MonoBitmap) Upscale(factor int, method Upscaler) MonoBitmap {
+	factor = max(factor, 1)
+	switch method {
+	case UPSCALE_SCALENX:
+		result := *p
+		for ; factor%3 == 0; factor /= 3 {
+			result = result.Scale3x()
+		}
+		for ; factor%2 == 0; factor /= 2 {
+			result = result.Scale2x()
+		}
+		return result.Scale(factor, factor)
+	case UPSCALE_SMOOTH:
+		return p.SmoothScale(factor)
+	}
+	return p.Scale(factor, factor)
+}
+
+// GetZoomedView is zooming in version of GetView. View (size w,h) starts from corner p0 of bitmap and each pixel is scaled by zoom with selected upscaler. Corner is clamped so view stays inside bitmap when possible. Area outside bitmap is filled with edges
+func (p *MonoBitmap) GetZoomedView(w int, h int, p0 image.Point, zoom int, method Upscaler, edges bool) MonoBitmap {
+	result, _ := p.zoomedView(w, h, p0, zoom, method, edges)
+	return result
+}
+
+// zoomedView returns also corner used
+func (p *MonoBitmap) zoomedView(w int, h int, p0 image.Point, zoom int, method Upscaler, edges bool) (MonoBitmap, image.Point) {
+	zoom = max(zoom, 1)
+	result := NewMonoBitmap(w, h, edges)
+	sw, sh := (w+zoom-1)/zoom, (h+zoom-1)/zoom
+	corner := image.Point{X: max(0, min(p0.X, p.W-sw)), Y: max(0, min(p0.Y, p.H-sh))}
+
+	//One pixel margin so upscaler sees real neighbours on view edges
+	area := image.Rect(corner.X-1, corner.Y-1, corner.X+sw+1, corner.Y+sh+1).Intersect(p.LocalBounds())
+	if area.Empty() {
+		return result, corner
+	}
+	view := p.SubBitmap(area)
+	scaled := view.Upscale(zoom, method)
+	start := corner.Sub(area.Min).Mul(zoom)
+	result.DrawBitmapOp(scaled, image.Rectangle{Min: start, Max: start.Add(image.Pt(w, h))}, image.Point{}, ROP_COPY)
+	return result, corner
+}
@@ -0,0 +1,215 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+// parseBitmap creates bitmap from rows where '#' is on
+func parseBitmap(rows ...string) gomonochromebitmap.MonoBitmap {
+	result := gomonochromebitmap.NewMonoBitmap(len(rows[0]), len(rows), false)
+	for y, row := range rows {
+		for x, c := range row {
+			result.SetPix(x, y, c == '#')
+		}
+	}
+	return result
+}
+
+// bitmapString is inverse of parseBitmap, for error messages
+func bitmapString(bm gomonochromebitmap.MonoBitmap) string {
+	var sb strings.Builder
+	for y := 0; y < bm.H; y++ {
+		sb.WriteByte('\n')
+		for x := 0; x < bm.W; x++ {
+			if bm.GetPixNoCheck(x, y) {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+	}
+	return sb.String()
+}
+
+func TestScale(t *testing.T) {
+	src := randomBitmap(rand.New(rand.NewSource(30)), 11, 7)
+	for _, size := range []image.Point{{1, 1}, {2, 3}, {40, 1}} {
+		result := src.Scale(size.X, size.Y)
+		if result.W != 11*size.X || result.H != 7*size.Y {
+			t.Fatalf("size %vx%v", result.W, result.H)
+		}
+		for y := 0; y < result.H; y++ {
+			for x := 0; x < result.W; x++ {
+				if result.GetPixNoCheck(x, y) != src.GetPixNoCheck(x/size.X, y/size.Y) {
+					t.Fatalf("scale %v failed at %v,%v", size, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestScale2x(t *testing.T) {
+	src := parseBitmap(
+		"......",
+		".#....",
+		"..#...",
+		"...##.",
+		"......",
+	)
+	expected := parseBitmap(
+		"............",
+		"............",
+		"..##........",
+		"..###.......",
+		"...###......",
+		"....###.....",
+		".....#####..",
+		"......####..",
+		"............",
+		"............",
+	)
+	if result := src.Scale2x(); !equalBitmaps(expected, result) {
+		t.Errorf("got %v", bitmapString(result))
+	}
+	if result := src.EPX(); !equalBitmaps(expected, result) {
+		t.Errorf("EPX differs")
+	}
+
+	//Corners of block are rounded
+	block := parseBitmap(
+		"......",
+		"..##..",
+		"..##..",
+		"......",
+	)
+	expected = parseBitmap(
+		"............",
+		"............",
+		".....##.....",
+		"....####....",
+		"....####....",
+		".....##.....",
+		"............",
+		"............",
+	)
+	if result := block.Scale2x(); !equalBitmaps(expected, result) {
+		t.Errorf("got %v", bitmapString(result))
+	}
+
+	//Straight edges stay straight
+	block = gomonochromebitmap.NewMonoBitmap(9, 9, false)
+	block.Fill(image.Rect(0, 3, 8, 8), true)
+	if result, nearest := block.Scale2x(), block.Scale(2, 2); !equalBitmaps(nearest, result) {
+		t.Errorf("half filled changed")
+	}
+	if result, nearest := block.Scale3x(), block.Scale(3, 3); !equalBitmaps(nearest, result) {
+		t.Errorf("half filled changed on 3x")
+	}
+}
+
+func TestScale3x(t *testing.T) {
+	src := parseBitmap(
+		"......",
+		".#....",
+		"..#...",
+		"...##.",
+		"......",
+	)
+	expected := parseBitmap(
+		"..................",
+		"..................",
+		"..................",
+		"...###............",
+		"...###............",
+		"...####...........",
+		".....####.........",
+		"......###.........",
+		"......#####.......",
+		"........#######...",
+		".........######...",
+		".........######...",
+		"..................",
+		"..................",
+		"..................",
+	)
+	if result := src.Scale3x(); !equalBitmaps(expected, result) {
+		t.Errorf("got %v", bitmapString(result))
+	}
+}
+
+func TestSmoothScale(t *testing.T) {
+	src := parseBitmap(
+		".....",
+		".##..",
+		".##..",
+		"...#.",
+		".....",
+	)
+	expected := parseBitmap(
+		"....................",
+		"....................",
+		"....................",
+		"....................",
+		"......####..........",
+		".....######.........",
+		"....########........",
+		"....########........",
+		"....########........",
+		"....########........",
+		".....######.#.......",
+		"......####..##......",
+		"..........######....",
+		"...........#####....",
+		"............####....",
+		"............####....",
+		"....................",
+		"....................",
+		"....................",
+		"....................",
+	)
+	if result := src.SmoothScale(4); !equalBitmaps(expected, result) {
+		t.Errorf("got %v", bitmapString(result))
+	}
+	if result := src.SmoothScale(2); !equalBitmaps(src.Scale2x(), result) {
+		t.Errorf("factor 2 differs from Scale2x")
+	}
+	tripled := src.Scale3x()
+	if result := src.Upscale(6, gomonochromebitmap.UPSCALE_SCALENX); !equalBitmaps(tripled.Scale2x(), result) {
+		t.Errorf("factor 6 is not Scale3x and Scale2x")
+	}
+}
+
+func TestZoomedView(t *testing.T) {
+	src := randomBitmap(rand.New(rand.NewSource(31)), 40, 30)
+	for _, method := range []gomonochromebitmap.Upscaler{gomonochromebitmap.UPSCALE_NEAREST, gomonochromebitmap.UPSCALE_SCALENX, gomonochromebitmap.UPSCALE_SMOOTH} {
+		full := src.Upscale(3, method)
+		view := src.GetZoomedView(32, 20, image.Pt(10, 5), 3, method, false)
+		if !equalBitmaps(full.SubBitmap(image.Rect(30, 15, 62, 35)), view) {
+			t.Errorf("method %v view differs from full scale", method)
+		}
+	}
+
+	//Corner is clamped and area outside small bitmap is edges
+	view, corner, step := src.GetView(128, 100, image.Pt(30, 0), -2, true)
+	doubled := src.Scale(2, 2)
+	if !equalBitmaps(doubled.SubBitmap(image.Rect(0, 0, 80, 60)), view.SubBitmap(image.Rect(0, 0, 80, 60))) {
+		t.Errorf("zoom in failed")
+	}
+	if !view.GetPix(80, 0) || !view.GetPix(0, 60) {
+		t.Errorf("edges not drawn")
+	}
+	if corner != (image.Point{}) || step != -2 {
+		t.Errorf("corner %v step %v", corner, step)
+	}
+
+	//Autoscale picks largest integer zoom
+	view, _, step = src.GetView(128, 100, image.Point{}, 0, false)
+	if !equalBitmaps(src.Scale(3, 3), view.SubBitmap(image.Rect(0, 0, 120, 90))) || view.GetPix(125, 95) || step != -3 {
+		t.Errorf("autoscale failed")
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Scaled views for display
+
+When view is zoomed out, each view pixel covers step*step block of source
+pixels. Coverage sampling counts on pixels of whole block so thin lines do
+not vanish like they do when only one source pixel is sampled. Pixels of
+block outside bitmap count as edges value.
+*/
+package gomonochromebitmap
+
+import (
+	"image"
+	"math"
+	"math/bits"
+)
+
+// ViewSampling selects how block of source pixels becomes one view pixel when zooming out
+type ViewSampling byte
+
+const (
+	VIEW_SAMPLE    ViewSampling = 0 // Corner pixel of block, fastest
+	VIEW_ANY       ViewSampling = 1 // On if any pixel is on, keeps thin lines
+	VIEW_ALL       ViewSampling = 2 // On only if all pixels are on, keeps thin gaps
+	VIEW_MAJORITY  ViewSampling = 3 // On if at least half of pixels are on
+	VIEW_THRESHOLD ViewSampling = 4 // On if coverage (0-255) is at least Threshold
+)
+
+// ViewOptions for GetViewWith
+type ViewOptions struct {
+	Sampling  ViewSampling
+	Threshold byte     // Used with VIEW_THRESHOLD
+	Upscaler  Upscaler // Used when zooming in
+	Edges     bool     // Value of pixels outside bitmap
+	Gray      bool     // Produce also coverage image, for dithering or grayscale preview
+}
+
+// View is result of GetViewWith
+type View struct {
+	Bitmap   MonoBitmap
+	Coverage *image.Gray // Share of on pixels under each view pixel, 255 is all. Nil if not requested
+	Corner   image.Point // Actual corner used
+	Step     int         // Actual step used, negative when zoomed in. Same way as pxStep on GetView
+}
+
+// GetViewWith works like GetView with sampling options
+func (p *MonoBitmap) GetViewWith(w int, h int, p0 image.Point, pxStep int, opt ViewOptions) View {
+	maxStep := math.Max(float64(p.W)/float64(w), float64(p.H)/float64(h)) //In decimal
+	corner := image.Point{X: max(p0.X, 0), Y: max(p0.Y, 0)}               //Limit point inside
+
+	step := min(pxStep, int(math.Ceil(maxStep))) //Limits zooming out too much
+	if pxStep == 0 {                             //Autoscale
+		step = int(math.Ceil(maxStep))
+		corner = image.Point{X: 0, Y: 0}
+		if 0 < maxStep && maxStep <= 0.5 { //Scale bigger by largest integer zoom that fits
+			step = -int(1 / maxStep)
+		}
+	}
+	if step == 0 { //Empty bitmap, view shows only edges
+		step = 1
+	}
+
+	if step < 0 {
+		var view View
+		view.Bitmap, view.Corner = p.zoomedView(w, h, corner, -step, opt.Upscaler, opt.Edges)
+		view.Step = step
+		if opt.Gray {
+			view.Coverage = view.Bitmap.grayCoverage()
+		}
+		return view
+	}
+
+	//Limit corner
+	corner.X = min(corner.X, p.W-step*w)
+	corner.Y = min(corner.Y, p.H-step*h)
+
+	view := View{Bitmap: NewMonoBitmap(w, h, false), Corner: corner, Step: step}
+	if opt.Gray {
+		view.Coverage = image.NewGray(image.Rect(0, 0, w, h))
+	}
+	bounds := p.LocalBounds()
+	n := step * step
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			block := image.Rect(0, 0, step, step).Add(corner).Add(image.Pt(x*step, y*step))
+			var on int
+			var value bool
+			if opt.Sampling == VIEW_SAMPLE {
+				value = opt.Edges
+				if block.Min.In(bounds) {
+					value = p.GetPixNoCheck(block.Min.X, block.Min.Y)
+				}
+				if value {
+					on = n
+				}
+			} else {
+				on = p.countBlock(block)
+				if opt.Edges {
+					in := block.Intersect(bounds)
+					on += n - in.Dx()*in.Dy()
+				}
+				switch opt.Sampling {
+				case VIEW_ANY:
+					value = 0 < on
+				case VIEW_ALL:
+					value = on == n
+				case VIEW_MAJORITY:
+					value = n <= 2*on
+				case VIEW_THRESHOLD:
+					value = opt.Threshold <= coverage(on, n)
+				}
+			}
+			view.Bitmap.SetPixNoCheck(x, y, value)
+			if view.Coverage != nil {
+				view.Coverage.Pix[y*view.Coverage.Stride+x] = coverage(on, n)
+			}
+		}
+	}
+	return view
+}
+
+// coverage scales on pixel count to 0-255
+func coverage(on int, n int) uint8 {
+	return uint8((on*255 + n/2) / n)
+}
+
+// countBlock returns number of on pixels in area. Area is clipped inside bitmap
+func (p *MonoBitmap) countBlock(area image.Rectangle) int {
+	area = area.Intersect(p.LocalBounds())
+	result := 0
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		bit := p.rowBit(y) + area.Min.X
+		n := area.Dx()
+		for ; 32 <= n; n -= 32 {
+			result += bits.OnesCount32(fetchBits(p.Pix, bit))
+			bit += 32
+		}
+		if 0 < n {
+			result += bits.OnesCount32(fetchBits(p.Pix, bit) & (1<<uint32(n) - 1))
+		}
+	}
+	return result
+}
+
+// grayCoverage converts bitmap to gray image, 255 is on
+func (p *MonoBitmap) grayCoverage() *image.Gray {
+	result := image.NewGray(p.LocalBounds())
+	for y := 0; y < p.H; y++ {
+		for x := 0; x < p.W; x++ {
+			if p.GetPixNoCheck(x, y) {
+				result.Pix[y*result.Stride+x] = 255
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package gomonochromebitmap_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+func TestViewCoverage(t *testing.T) {
+	//Thin line and thin gap on full area
+	src := gomonochromebitmap.NewMonoBitmap(64, 64, false)
+	src.Vline(5, 0, 63, true)
+	src.Fill(image.Rect(32, 0, 63, 63), true)
+	src.Vline(45, 0, 63, false)
+
+	tests := []struct {
+		sampling gomonochromebitmap.ViewSampling
+		line     bool
+		gap      bool
+	}{
+		{gomonochromebitmap.VIEW_SAMPLE, false, true},
+		{gomonochromebitmap.VIEW_ANY, true, true},
+		{gomonochromebitmap.VIEW_ALL, false, false},
+		{gomonochromebitmap.VIEW_MAJORITY, false, true},
+	}
+	for _, test := range tests {
+		view := src.GetViewWith(16, 16, image.Point{}, 4, gomonochromebitmap.ViewOptions{Sampling: test.sampling})
+		if view.Bitmap.GetPix(1, 8) != test.line || view.Bitmap.GetPix(11, 8) != test.gap {
+			t.Errorf("sampling %v line %v gap %v", test.sampling, view.Bitmap.GetPix(1, 8), view.Bitmap.GetPix(11, 8))
+		}
+		if !view.Bitmap.GetPix(10, 0) || view.Bitmap.GetPix(4, 0) {
+			t.Errorf("sampling %v changed full blocks", test.sampling)
+		}
+	}
+}
+
+func TestViewThresholdAndGray(t *testing.T) {
+	checker := gomonochromebitmap.NewMonoBitmap(40, 40, false)
+	for y := 0; y < 40; y++ {
+		for x := (y & 1); x < 40; x += 2 {
+			checker.SetPix(x, y, true)
+		}
+	}
+	view := checker.GetViewWith(10, 10, image.Point{}, 4, gomonochromebitmap.ViewOptions{Sampling: gomonochromebitmap.VIEW_THRESHOLD, Threshold: 128, Gray: true})
+	if view.Coverage == nil || view.Coverage.GrayAt(3, 3).Y != 128 || !view.Bitmap.GetPix(3, 3) {
+		t.Errorf("threshold 128 failed")
+	}
+	view = checker.GetViewWith(10, 10, image.Point{}, 4, gomonochromebitmap.ViewOptions{Sampling: gomonochromebitmap.VIEW_THRESHOLD, Threshold: 129})
+	if view.Coverage != nil || view.Bitmap.GetPix(3, 3) {
+		t.Errorf("threshold 129 failed")
+	}
+
+	//Area outside bitmap counts as edges. Small bitmap is on lower right corner of view
+	small := gomonochromebitmap.NewMonoBitmap(10, 10, false)
+	view = small.GetViewWith(8, 8, image.Point{}, 2, gomonochromebitmap.ViewOptions{Sampling: gomonochromebitmap.VIEW_ANY, Edges: true, Gray: true})
+	if view.Corner != image.Pt(-6, -6) || view.Bitmap.GetPix(4, 4) || !view.Bitmap.GetPix(2, 7) || view.Coverage.GrayAt(0, 0).Y != 255 {
+		t.Errorf("edges failed")
+	}
+
+	//Zoomed in gray is bitmap
+	small.SetPix(1, 1, true)
+	view = small.GetViewWith(20, 20, image.Point{}, 0, gomonochromebitmap.ViewOptions{Gray: true, Upscaler: gomonochromebitmap.UPSCALE_SMOOTH})
+	if view.Step != -2 || view.Coverage.GrayAt(3, 3).Y != 255 || view.Coverage.GrayAt(0, 0).Y != 0 {
+		t.Errorf("zoomed gray failed")
+	}
+}
+
+func TestViewCornerAndStep(t *testing.T) {
+	src := gomonochromebitmap.NewMonoBitmap(256, 256, false)
+	tests := []struct {
+		p0     image.Point
+		pxStep int
+		corner image.Point
+		step   int
+	}{
+		{image.Pt(300, 10), 2, image.Pt(128, 10), 2},
+		{image.Pt(10, 10), 10, image.Pt(0, 0), 4},
+		{image.Pt(-5, 20), 1, image.Pt(0, 20), 1},
+		{image.Pt(50, 50), 0, image.Pt(0, 0), 4},
+		{image.Pt(250, 100), -2, image.Pt(224, 100), -2},
+	}
+	for _, test := range tests {
+		_, corner, step := src.GetView(64, 64, test.p0, test.pxStep, false)
+		if corner != test.corner || step != test.step {
+			t.Errorf("p0 %v pxStep %v got corner %v step %v", test.p0, test.pxStep, corner, step)
+		}
+	}
+}
+
+func TestViewEmptySource(t *testing.T) {
+	for _, size := range []image.Point{{0, 0}, {10, 0}, {0, 10}} {
+		src := gomonochromebitmap.NewMonoBitmap(size.X, size.Y, false)
+		for _, pxStep := range []int{0, 1, 3} {
+			for _, sampling := range []gomonochromebitmap.ViewSampling{gomonochromebitmap.VIEW_SAMPLE, gomonochromebitmap.VIEW_THRESHOLD} {
+				view := src.GetViewWith(10, 10, image.Point{}, pxStep, gomonochromebitmap.ViewOptions{Sampling: sampling, Threshold: 128, Edges: true, Gray: true})
+				if view.Bitmap.W != 10 || view.Bitmap.H != 10 || countOn(view.Bitmap) != 100 || view.Coverage.GrayAt(5, 5).Y != 255 {
+					t.Errorf("%vx%v step %v sampling %v: view is not edges", size.X, size.Y, pxStep, sampling)
+				}
+			}
+		}
+	}
+}
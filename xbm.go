@@ -0,0 +1,118 @@
+/*
+X11 bitmap (XBM) reading and writing
+
+XBM is C source. Rows are padded to bytes, first pixel is least significant
+bit. Set bits are foreground and they are on pixels on MonoBitmap.
+*/
+package gomonochromebitmap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	xbmDefine = regexp.MustCompile(`#define\s+(\S+?)_(width|height|x_hot|y_hot)\s+(\d+)`)
+	xbmArray  = regexp.MustCompile(`static\s+(?:unsigned\s+)?(char|short)\s+(\S+?)_bits\s*\[\s*\]\s*=\s*\{`)
+	xbmNumber = regexp.MustCompile(`0[xX][0-9a-fA-F]+|\d+`)
+)
+
+// XBM is parsed X11 bitmap
+type XBM struct {
+	Name    string
+	Bitmap  MonoBitmap
+	Hotspot *[2]int //x,y if defined
+}
+
+// DecodeXBM parses XBM file. Both X11 (char) and X10 (short) data are supported
+func DecodeXBM(r io.Reader) (XBM, error) {
+	raw, errRead := io.ReadAll(r)
+	if errRead != nil {
+		return XBM{}, errRead
+	}
+	src := string(raw)
+
+	result := XBM{}
+	w, h := -1, -1
+	hot := [2]int{-1, -1}
+	for _, m := range xbmDefine.FindAllStringSubmatch(src, -1) {
+		v, _ := strconv.Atoi(m[3])
+		switch m[2] {
+		case "width":
+			w = v
+			result.Name = m[1]
+		case "height":
+			h = v
+		case "x_hot":
+			hot[0] = v
+		case "y_hot":
+			hot[1] = v
+		}
+	}
+	if w <= 0 || h <= 0 {
+		return XBM{}, fmt.Errorf("XBM width or height not defined")
+	}
+	if errSize := checkDecodeSize(w, h); errSize != nil {
+		return XBM{}, fmt.Errorf("XBM header: %w", errSize)
+	}
+	if 0 <= hot[0] && 0 <= hot[1] {
+		result.Hotspot = &hot
+	}
+
+	loc := xbmArray.FindStringSubmatchIndex(src)
+	if loc == nil {
+		return XBM{}, fmt.Errorf("XBM bits array not found")
+	}
+	wordBits := 8
+	if src[loc[2]:loc[3]] == "short" {
+		wordBits = 16
+	}
+	body := src[loc[1]:]
+	end := strings.IndexByte(body, '}')
+	if end < 0 {
+		return XBM{}, fmt.Errorf("XBM bits array not terminated")
+	}
+
+	//Unpack words into bytes, least significant byte first
+	data := []byte{}
+	for _, s := range xbmNumber.FindAllString(body[:end], -1) {
+		v, err := strconv.ParseUint(s, 0, wordBits)
+		if err != nil {
+			return XBM{}, fmt.Errorf("invalid XBM value %s: %w", s, err)
+		}
+		data = append(data, byte(v))
+		if wordBits == 16 {
+			data = append(data, byte(v>>8))
+		}
+	}
+	if wordBits == 16 { //Rows are padded to 16 bits, repack to bytes
+		rowWords := (w + 15) / 16
+		packed := make([]byte, 0, h*((w+7)/8))
+		for y := 0; y < h && (y+1)*rowWords*2 <= len(data); y++ {
+			packed = append(packed, data[y*rowWords*2:y*rowWords*2+(w+7)/8]...)
+		}
+		data = packed
+	}
+
+	bm, errUnpack := UnpackBytes(data, w, h, PACK_HORIZONTAL, LSB_FIRST)
+	if errUnpack != nil {
+		return XBM{}, errUnpack
+	}
+	result.Bitmap = bm
+	return result, nil
+}
+
+// EncodeXBM writes bitmap as X11 XBM. On pixels are set bits
+func EncodeXBM(w io.Writer, bm *MonoBitmap, name string) error {
+	out := bufio.NewWriter(w)
+	name = SourceIdentifier(name)
+	fmt.Fprintf(out, "#define %s_width %v\n#define %s_height %v\n", name, bm.W, name, bm.H)
+	fmt.Fprintf(out, "static unsigned char %s_bits[] = {\n", name)
+	writeHexBytes(out, bm.PackBytes(PACK_HORIZONTAL, LSB_FIRST), 12, "   ", false)
+	out.WriteString("};\n")
+	return out.Flush()
+}
@@ -0,0 +1,77 @@
+package gomonochromebitmap_test
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/hjkoskel/gomonochromebitmap"
+)
+
+const xbmExample = `#define test_width 10
+#define test_height 3
+#define test_x_hot 4
+#define test_y_hot 1
+static unsigned char test_bits[] = {
+   0x01, 0x02, 0xff, 0x03,
+   0x80, 0x00 };
+`
+
+func TestXBMDecode(t *testing.T) {
+	x, err := gomonochromebitmap.DecodeXBM(strings.NewReader(xbmExample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Name != "test" || x.Bitmap.W != 10 || x.Bitmap.H != 3 {
+		t.Fatalf("invalid header %v %vx%v", x.Name, x.Bitmap.W, x.Bitmap.H)
+	}
+	if x.Hotspot == nil || x.Hotspot[0] != 4 || x.Hotspot[1] != 1 {
+		t.Errorf("hotspot not parsed %v", x.Hotspot)
+	}
+	expected := []string{"#........#", "##########", ".......#.."}
+	for y, row := range expected {
+		for px, c := range row {
+			if x.Bitmap.GetPix(px, y) != (c == '#') {
+				t.Errorf("pixel %v,%v invalid", px, y)
+			}
+		}
+	}
+}
+
+func TestXBMDecodeX10(t *testing.T) {
+	x10 := "#define old_width 17\n#define old_height 2\nstatic short old_bits[] = {\n 0x0001, 0x0001, 0x8000, 0x0000};\n"
+	x, err := gomonochromebitmap.DecodeXBM(strings.NewReader(x10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !x.Bitmap.GetPix(0, 0) || !x.Bitmap.GetPix(16, 0) || !x.Bitmap.GetPix(15, 1) || x.Bitmap.GetPix(16, 1) {
+		t.Errorf("X10 bitmap not decoded")
+	}
+}
+
+func TestXBMHostileSize(t *testing.T) {
+	huge := "#define a_width 4000000\n#define a_height 4000000\nstatic short a_bits[] = { 0x00 };\n"
+	if _, err := gomonochromebitmap.DecodeXBM(strings.NewReader(huge)); err == nil {
+		t.Errorf("no error on too large XBM")
+	}
+}
+
+func TestXBMRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	bm := randomBitmap(rnd, 21, 9)
+	var buf bytes.Buffer
+	if err := gomonochromebitmap.EncodeXBM(&buf, &bm, "my icon"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "#define my_icon_width 21") {
+		t.Errorf("unexpected output\n%s", buf.String())
+	}
+	x, err := gomonochromebitmap.DecodeXBM(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalBitmaps(bm, x.Bitmap) || x.Hotspot != nil {
+		t.Errorf("round trip failed")
+	}
+}